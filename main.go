@@ -1,46 +1,417 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/daemon"
+	"github.com/supporttools/rancher-projects/pkg/guide"
 	"github.com/supporttools/rancher-projects/pkg/logging"
+	"github.com/supporttools/rancher-projects/pkg/metrics"
 	"github.com/supporttools/rancher-projects/pkg/rancher"
+	"github.com/supporttools/rancher-projects/pkg/rules"
+	"github.com/supporttools/rancher-projects/pkg/server"
 )
 
 var logger = logging.SetupLogging()
 
+// Exit codes. Invalid configuration exits with 1 and unparsable flags with 2, see config.Init.
+const (
+	exitError = 1
+	// exitFindings is returned when drift finds a difference, orphans with --fail-on-orphans finds
+	// a namespace, or check finds diverged Pod Security labels, so CI can treat them all the same way.
+	exitFindings = 3
+)
+
 func main() {
-	// Initialize configuration
-	config.Init()
+	os.Exit(run())
+}
 
-	// Get the configuration
-	cfg := config.GetConfig()
+// run executes the configured command and returns the exit code. Returning instead of calling
+// os.Exit lets the deferred cleanup flush the cassette and the audit log.
+func run() int {
+	// Load and validate the configuration
+	cfg := config.Init()
 
 	if cfg.ShowHelp {
 		config.PrintHelp()
-		return
+		return 0
+	}
+
+	// Reports and prompts are written to stdout, so keep the log out of their way.
+	if cfg.Command == "drift" || cfg.Command == "export" || cfg.Command == "orphans" || cfg.Command == "check" || cfg.Command == "inventory" || cfg.Command == "guide" {
+		logger.SetOutput(os.Stderr)
 	}
 
 	logger.Info("Starting Rancher-Projects...")
 
+	cassetteFile, err := rancher.OpenCassette(cfg)
+	if err != nil {
+		logger.Error("Failed to open cassette: ", err)
+		return exitError
+	}
+	if cassetteFile != nil {
+		defer func() {
+			if err := cassetteFile.Close(); err != nil {
+				logger.Error("Failed to close cassette: ", err)
+			}
+		}()
+	}
+
+	auditLog, err := rancher.OpenAuditLog(cfg)
+	if err != nil {
+		logger.Error("Failed to open audit log: ", err)
+		return exitError
+	}
+	if auditLog != nil {
+		defer func() {
+			if err := auditLog.Close(); err != nil {
+				logger.Error("Failed to close audit log: ", err)
+			}
+		}()
+	}
+
+	if cfg.BootstrapCACerts {
+		if err := rancher.BootstrapCACerts(cfg); err != nil {
+			logger.Error("Failed to bootstrap trust from Rancher: ", err)
+			return exitError
+		}
+	}
+
 	// Verify access to Rancher
 	logger.Info("Verifying access to Rancher...")
 	if err := rancher.VerifyAccess(cfg); err != nil {
 		logger.Error("Failed to verify access to Rancher: ", err)
-		return
+		return exitError
+	}
+
+	switch cfg.Command {
+	case "drift":
+		return drift(cfg)
+	case "export":
+		return export(cfg)
+	case "orphans":
+		return orphans(cfg)
+	case "check":
+		return check(cfg)
+	case "inventory":
+		return inventory(cfg)
+	case "guide":
+		return runGuide(cfg)
+	case "assign":
+		if err := applyRules(cfg); err != nil {
+			return exitError
+		}
+		return 0
+	case "snapshot":
+		return snapshot(cfg)
+	case "restore":
+		return restore(cfg)
+	case "replicate":
+		return replicate(cfg)
+	case "daemon":
+		return runDaemon(cfg)
+	case "serve":
+		return serve(cfg)
+	default:
+		return apply(cfg)
+	}
+}
+
+// apply creates and assigns the configured project and namespace, or applies the manifest once.
+func apply(cfg *config.Config) int {
+	if cfg.ManifestFile != "" {
+		if err := reconcile(cfg); err != nil {
+			return exitError
+		}
+		return 0
 	}
 
 	// Determine if handling a single cluster or multiple clusters
-	if cfg.ClusterType == "" && cfg.ClusterLabels == "" {
+	if !cfg.SelectsClusters() {
 		logger.Info("Processing a single cluster...")
 		if err := rancher.SingleCluster(cfg); err != nil {
 			logger.Error("Failed to handle single cluster: ", err)
-			return
+			return exitError
 		}
 	} else {
 		logger.Info("Processing multiple clusters...")
 		if err := rancher.MultiCluster(cfg); err != nil {
 			logger.Error("Failed to handle multiple clusters: ", err)
-			return
+			return exitError
+		}
+	}
+	return 0
+}
+
+// drift prints the drift report and returns the exit code.
+func drift(cfg *config.Config) int {
+	desired, err := rancher.DesiredState(cfg)
+	if err != nil {
+		logger.Error("Failed to load desired state: ", err)
+		return exitError
+	}
+
+	report, err := rancher.DetectDrift(cfg, desired)
+	if err != nil {
+		logger.Error("Failed to detect drift: ", err)
+		return exitError
+	}
+	if err := report.Write(os.Stdout, cfg.Output); err != nil {
+		logger.Error("Failed to write drift report: ", err)
+		return exitError
+	}
+
+	switch {
+	case report.HasErrors():
+		return exitError
+	case report.Drift:
+		return exitFindings
+	}
+	return 0
+}
+
+// orphans prints the namespaces outside any project and returns the exit code.
+func orphans(cfg *config.Config) int {
+	report, err := rancher.FindOrphans(cfg)
+	if err != nil {
+		logger.Error("Failed to find orphaned namespaces: ", err)
+		return exitError
+	}
+	if err := report.Write(os.Stdout, cfg.Output); err != nil {
+		logger.Error("Failed to write orphan report: ", err)
+		return exitError
+	}
+
+	switch {
+	case report.HasErrors():
+		return exitError
+	case report.Orphans && cfg.FailOnOrphans:
+		return exitFindings
+	}
+	return 0
+}
+
+// check prints the namespaces whose Pod Security labels differ from their project's level and
+// returns the exit code.
+func check(cfg *config.Config) int {
+	report, err := rancher.CheckPodSecurity(cfg)
+	if err != nil {
+		logger.Error("Failed to check Pod Security labels: ", err)
+		return exitError
+	}
+	if err := report.Write(os.Stdout, cfg.Output); err != nil {
+		logger.Error("Failed to write Pod Security report: ", err)
+		return exitError
+	}
+
+	switch {
+	case report.HasErrors():
+		return exitError
+	case report.Diverged:
+		return exitFindings
+	}
+	return 0
+}
+
+// inventory prints the selected clusters and returns the exit code.
+func inventory(cfg *config.Config) int {
+	report, err := rancher.ClusterInventory(cfg)
+	if err != nil {
+		logger.Error("Failed to build cluster inventory: ", err)
+		return exitError
+	}
+	if err := report.Write(os.Stdout, cfg.Output); err != nil {
+		logger.Error("Failed to write cluster inventory: ", err)
+		return exitError
+	}
+	return 0
+}
+
+// runGuide guides the operator through the assignment and returns the exit code.
+func runGuide(cfg *config.Config) int {
+	err := guide.New(cfg, os.Stdin, os.Stdout).Run()
+	switch {
+	case errors.Is(err, guide.ErrAborted):
+		fmt.Println("Aborted, nothing was changed.")
+		return exitError
+	case err != nil:
+		logger.Error("Guided session failed: ", err)
+		return exitError
+	}
+	return 0
+}
+
+// export writes the projects and namespaces of the selected clusters as a manifest to stdout.
+func export(cfg *config.Config) int {
+	exported, err := rancher.ExportManifest(cfg)
+	if err != nil {
+		logger.Error("Failed to export manifest: ", err)
+		return exitError
+	}
+	if err := exported.Write(os.Stdout, cfg.Output); err != nil {
+		logger.Error("Failed to write manifest: ", err)
+		return exitError
+	}
+	return 0
+}
+
+// snapshot saves the namespace assignments of the selected clusters to the snapshot file.
+func snapshot(cfg *config.Config) int {
+	s, err := rancher.TakeSnapshot(cfg)
+	if err != nil {
+		logger.Error("Failed to take snapshot: ", err)
+		return exitError
+	}
+	if err := s.Save(cfg.SnapshotFile); err != nil {
+		logger.Error("Failed to save snapshot: ", err)
+		return exitError
+	}
+	logger.Info(fmt.Sprintf("Saved snapshot of %d cluster(s) to %s", len(s.Clusters), cfg.SnapshotFile))
+	return 0
+}
+
+// restore moves namespaces back to the projects recorded in the snapshot file.
+func restore(cfg *config.Config) int {
+	s, err := rancher.LoadSnapshot(cfg.SnapshotFile)
+	if err != nil {
+		logger.Error("Failed to load snapshot: ", err)
+		return exitError
+	}
+
+	summary, err := rancher.RestoreSnapshot(cfg, s)
+	if err != nil {
+		logger.Error("Restore failed: ", err)
+		return exitError
+	}
+	if len(summary.Errors) > 0 {
+		logger.Error(fmt.Sprintf("Restore finished with errors: %s", summary))
+		return exitError
+	}
+	logger.Info(fmt.Sprintf("Restore finished: %s", summary))
+	return 0
+}
+
+// replicate copies the project structure of the source cluster to the selected clusters.
+func replicate(cfg *config.Config) int {
+	summary, err := rancher.ReplicateProjects(cfg, cfg.SourceCluster)
+	if err != nil {
+		logger.Error("Replication failed: ", err)
+		return exitError
+	}
+	if len(summary.Errors) > 0 {
+		logger.Error(fmt.Sprintf("Replication finished with errors: %s", summary))
+		return exitError
+	}
+	logger.Info(fmt.Sprintf("Replication finished: %s", summary))
+	return 0
+}
+
+// runDaemon reconciles the selected clusters every interval until SIGINT or SIGTERM is received,
+// or until the metrics server fails.
+func runDaemon(cfg *config.Config) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	metricsFailed := make(chan struct{})
+	if cfg.MetricsAddress != "" {
+		go func() {
+			logger.Info(fmt.Sprintf("Serving metrics on %s", cfg.MetricsAddress))
+			if err := metrics.ListenAndServe(ctx, cfg.MetricsAddress); err != nil {
+				logger.Error("Metrics server failed: ", err)
+				close(metricsFailed)
+				stop()
+			}
+		}()
+	}
+
+	logger.Info(fmt.Sprintf("Starting reconcile daemon with interval %s and jitter %s", cfg.Interval, cfg.Jitter))
+	cycle := 0
+	daemon.Run(ctx, cfg.Interval, cfg.Jitter, func(context.Context) {
+		cycle++
+		logger.Info(fmt.Sprintf("Starting reconcile cycle %d...", cycle))
+		if cfg.ManifestFile != "" || cfg.ProjectName != "" {
+			_ = reconcile(cfg)
+		}
+		if cfg.RulesFile != "" {
+			_ = applyRules(cfg)
 		}
+	})
+	logger.Info("Reconcile daemon stopped")
+
+	select {
+	case <-metricsFailed:
+		return exitError
+	default:
+		return 0
+	}
+}
+
+// reconcile runs a single reconcile cycle and logs its summary. The desired state is loaded
+// on every call so manifest changes are picked up without a restart.
+func reconcile(cfg *config.Config) error {
+	desired, err := rancher.DesiredState(cfg)
+	if err != nil {
+		logger.Error("Failed to load desired state: ", err)
+		return err
+	}
+
+	summary, err := rancher.Reconcile(cfg, desired)
+	if err != nil {
+		logger.Error("Reconcile failed: ", err)
+		return err
+	}
+
+	if len(summary.Errors) > 0 {
+		logger.Warn(fmt.Sprintf("Reconcile finished with errors: %s", summary))
+		return fmt.Errorf("reconcile failed for %d cluster(s)", len(summary.Errors))
+	}
+	logger.Info(fmt.Sprintf("Reconcile finished: %s", summary))
+	return nil
+}
+
+// applyRules assigns namespaces to projects according to the rules file and logs the summary.
+// The rules are loaded on every call so changes are picked up by the daemon without a restart.
+func applyRules(cfg *config.Config) error {
+	r, err := rules.Load(cfg.RulesFile)
+	if err != nil {
+		logger.Error("Failed to load rules: ", err)
+		return err
+	}
+
+	summary, err := rancher.ApplyRules(cfg, r)
+	if err != nil {
+		logger.Error("Applying rules failed: ", err)
+		return err
+	}
+
+	if len(summary.Errors) > 0 {
+		logger.Warn(fmt.Sprintf("Rules applied with errors: %s", summary))
+		return fmt.Errorf("applying rules failed for %d cluster(s)", len(summary.Errors))
+	}
+	logger.Info(fmt.Sprintf("Rules applied: %s", summary))
+	return nil
+}
+
+// serve runs the self-service API until SIGINT or SIGTERM is received.
+func serve(cfg *config.Config) int {
+	keys, err := server.LoadAPIKeys(cfg.APIKeysFile)
+	if err != nil {
+		logger.Error("Failed to load API keys: ", err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := server.New(cfg, keys).ListenAndServe(ctx, cfg.ListenAddress); err != nil {
+		logger.Error("API server failed: ", err)
+		return exitError
 	}
+	return 0
 }
@@ -0,0 +1,117 @@
+// Package audit writes an append-only JSON Lines record of every change rancher-projects
+// makes through the Rancher API.
+//
+// Each line is one Entry. Entries are written regardless of the log level, and the file is
+// only ever opened for appending, so existing records are never rewritten.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionCreateProject       = "project.create"
+	ActionUpdateProject       = "project.update"
+	ActionAddProjectMember    = "project.member.create"
+	ActionIsolateProject      = "project.isolate"
+	ActionCreateNamespace     = "namespace.create"
+	ActionAssignNamespace     = "namespace.assign"
+	ActionCreateNetworkPolicy = "networkpolicy.create"
+	ActionGenerateKubeconfig  = "kubeconfig.generate"
+)
+
+// Outcomes of an audited action.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	// OutcomeUnchanged means the action was not needed, e.g. the namespace already existed.
+	OutcomeUnchanged = "unchanged"
+)
+
+// Entry is a single audited action.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Operator  string    `json:"operator"`
+	Caller    string    `json:"caller,omitempty"`
+	Action    string    `json:"action"`
+	Server    string    `json:"server,omitempty"`
+	Cluster   string    `json:"cluster,omitempty"`
+	ClusterID string    `json:"clusterId,omitempty"`
+	Object    string    `json:"object"`
+	Before    string    `json:"before,omitempty"`
+	After     string    `json:"after,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Log appends entries to a file. It is safe for concurrent use.
+type Log struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens, or creates, the audit log at path for appending.
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &Log{path: path, file: file}, nil
+}
+
+// Path returns the file the log appends to.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Write appends e as a single line. A zero Time is set to the current time.
+func (l *Log) Write(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// A single write per entry keeps lines intact when several processes share the file.
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// Close closes the underlying file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Read returns all entries in the audit log at path.
+func Read(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	var entries []Entry
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var e Entry
+		if err := decoder.Decode(&e); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log %s: %w", path, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
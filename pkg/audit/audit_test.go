@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	l, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, l.Write(Entry{Operator: "admin", Action: ActionCreateProject, Object: "project/Team", After: "c-1:p-1", Outcome: OutcomeSuccess}))
+	require.NoError(t, l.Close())
+
+	// Reopening appends instead of truncating.
+	l, err = Open(path)
+	require.NoError(t, err)
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, l.Write(Entry{Time: at, Operator: "admin", Action: ActionAssignNamespace, Object: "namespace/team", Outcome: OutcomeFailure, Error: "denied"}))
+	require.NoError(t, l.Close())
+
+	entries, err := Read(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.False(t, entries[0].Time.IsZero())
+	assert.Equal(t, "c-1:p-1", entries[0].After)
+	assert.Equal(t, at, entries[1].Time)
+	assert.Equal(t, "denied", entries[1].Error)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
@@ -0,0 +1,262 @@
+// Package cassette records HTTP interactions with the Rancher API to a file and
+// replays them later, so a run against a real server can be reproduced offline.
+//
+// Requests are stored without headers and with their path and query only, so a
+// cassette recorded against one server replays regardless of the server URL.
+// Credentials and kubeconfig tokens are scrubbed before anything is written.
+//
+// A cassette file is JSON Lines: a header with the format version and server URL,
+// followed by one line per interaction in the order they were recorded.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Version is the cassette file format version.
+const Version = 2
+
+// Cassette is a sequence of recorded HTTP interactions backed by a file.
+type Cassette struct {
+	Version int
+	Server  string
+	// Interactions holds the interactions loaded for replay. Recorded interactions are
+	// only written to the file.
+	Interactions []Interaction
+
+	path string
+	mu   sync.Mutex
+	used []bool
+	file *os.File
+}
+
+// header is the first line of a cassette file.
+type header struct {
+	Version int    `json:"version"`
+	Server  string `json:"server,omitempty"`
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request. URL holds the path and query only.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Create starts a new cassette file at path, replacing any existing file, and keeps it
+// open for the Recorder to append to until Close is called.
+func Create(path, server string) (*Cassette, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %w", err)
+	}
+	c := &Cassette{Version: Version, Server: server, path: path, file: file}
+	if err := c.writeLine(header{Version: Version, Server: server}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load reads a cassette from path.
+func Load(path string) (*Cassette, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	var h header
+	if err := decoder.Decode(&h); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	if h.Version != Version {
+		return nil, fmt.Errorf("unsupported cassette version %d in %s", h.Version, path)
+	}
+	c := &Cassette{Version: h.Version, Server: h.Server, path: path}
+	for {
+		var interaction Interaction
+		err := decoder.Decode(&interaction)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse interaction %d of cassette %s: %w", len(c.Interactions)+1, path, err)
+		}
+		c.Interactions = append(c.Interactions, interaction)
+	}
+	c.used = make([]bool, len(c.Interactions))
+	return c, nil
+}
+
+// Path returns the file the cassette is saved to.
+func (c *Cassette) Path() string {
+	return c.path
+}
+
+// Close closes the file of a cassette being recorded. It does nothing for a loaded cassette.
+func (c *Cassette) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close cassette: %w", err)
+	}
+	return nil
+}
+
+// writeLine appends v as one JSON line to the cassette file. c.mu must be held, except
+// while the cassette is being created.
+func (c *Cassette) writeLine(v interface{}) error {
+	if c.file == nil {
+		return fmt.Errorf("cassette %s is closed", c.path)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Recorder returns a transport that sends requests through next and appends every
+// interaction, scrubbed, as a line of the cassette file. Each line is written as soon as
+// the response is read, so a crashed run still leaves a usable file behind.
+func (c *Cassette) Recorder(next http.RoundTripper, scrubber *Scrubber) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		reqBody, err := readBody(&req.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		respBody, err := readBody(&resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		header := resp.Header.Clone()
+		header.Del("Set-Cookie")
+		interaction := Interaction{
+			Request: Request{
+				Method: req.Method,
+				URL:    scrubber.Scrub(req.URL.RequestURI()),
+				Body:   scrubber.Scrub(reqBody),
+			},
+			Response: Response{
+				StatusCode: resp.StatusCode,
+				Header:     header,
+				Body:       scrubber.Scrub(respBody),
+			},
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err := c.writeLine(interaction); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	})
+}
+
+// Replayer returns a transport that answers requests from the cassette without any
+// network access. Each request is matched, after scrubbing, against the first unused
+// interaction with the same method, URL and body.
+func (c *Cassette) Replayer(scrubber *Scrubber) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := readBody(&req.Body)
+		if err != nil {
+			return nil, err
+		}
+		want := Request{
+			Method: req.Method,
+			URL:    scrubber.Scrub(req.URL.RequestURI()),
+			Body:   scrubber.Scrub(body),
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, interaction := range c.Interactions {
+			if c.used[i] || interaction.Request != want {
+				continue
+			}
+			c.used[i] = true
+			return &http.Response{
+				Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+				StatusCode:    interaction.Response.StatusCode,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        interaction.Response.Header.Clone(),
+				Body:          io.NopCloser(bytes.NewBufferString(interaction.Response.Body)),
+				ContentLength: int64(len(interaction.Response.Body)),
+				Request:       req,
+			}, nil
+		}
+		return nil, fmt.Errorf("no recorded interaction for %s %s in cassette %s", want.Method, want.URL, c.path)
+	})
+}
+
+// Unused returns the number of recorded interactions that have not been replayed.
+func (c *Cassette) Unused() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := 0
+	for _, used := range c.used {
+		if !used {
+			count++
+		}
+	}
+	return count
+}
+
+// readBody reads and replaces *body so it can be read again.
+func readBody(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read body: %w", err)
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,57 @@
+package cassette
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces scrubbed values in a cassette.
+const Redacted = "REDACTED"
+
+var scrubPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// Kubeconfig user tokens, also when the kubeconfig is embedded in a JSON string.
+	{regexp.MustCompile(`(token:\s*(?:\\"|")?)[^"\\\s]+`), "${1}" + Redacted},
+	// Token fields of Rancher API objects.
+	{regexp.MustCompile(`("token"\s*:\s*")[^"]*`), "${1}" + Redacted},
+	// Rancher API keys of the form token-xxxxx:secret.
+	{regexp.MustCompile(`(token-[a-z0-9]+):[A-Za-z0-9]+`), "${1}:" + Redacted},
+}
+
+// Scrubber removes credentials from recorded requests and responses.
+type Scrubber struct {
+	secrets []string
+}
+
+// NewScrubber returns a scrubber that redacts kubeconfig and API tokens as well as
+// every non-empty secret given.
+func NewScrubber(secrets ...string) *Scrubber {
+	s := &Scrubber{}
+	for _, secret := range secrets {
+		if secret != "" {
+			s.secrets = append(s.secrets, secret)
+		}
+	}
+	// Longer secrets first so a secret containing another is redacted as a whole.
+	sort.Slice(s.secrets, func(i, j int) bool { return len(s.secrets[i]) > len(s.secrets[j]) })
+	return s
+}
+
+// Scrub returns value with all credentials redacted.
+func (s *Scrubber) Scrub(value string) string {
+	if value == "" {
+		return value
+	}
+	if s != nil {
+		for _, secret := range s.secrets {
+			value = strings.ReplaceAll(value, secret, Redacted)
+		}
+	}
+	for _, p := range scrubPatterns {
+		value = p.re.ReplaceAllString(value, p.repl)
+	}
+	return value
+}
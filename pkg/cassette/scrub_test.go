@@ -0,0 +1,29 @@
+package cassette
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrub(t *testing.T) {
+	s := NewScrubber("supersecret", "")
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"secret", `{"password":"supersecret"}`, `{"password":"REDACTED"}`},
+		{"api key", "Bearer token-abc12:xyz789", "Bearer token-abc12:REDACTED"},
+		{"token field", `{"token": "kubeconfig-u-abc:def"}`, `{"token": "REDACTED"}`},
+		{"kubeconfig", "user:\n    token: kubeconfig-user-abc:def\n", "user:\n    token: REDACTED\n"},
+		{"kubeconfig in json", `{"config":"users:\n  user:\n    token: \"kubeconfig-user-abc:def\"\n"}`, `{"config":"users:\n  user:\n    token: \"REDACTED\"\n"}`},
+		{"untouched", `{"name":"monitoring"}`, `{"name":"monitoring"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.Scrub(tt.value))
+		})
+	}
+}
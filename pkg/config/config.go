@@ -1,22 +1,59 @@
 package config
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"path"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/kubeversion"
+	"github.com/supporttools/rancher-projects/pkg/podsecurity"
 )
 
 type Config struct {
+	Command               string
+	ManifestFile          string
+	SnapshotFile          string
+	AllowOtherServer      bool
+	SourceCluster         string
+	RulesFile             string
+	ExcludeNamespaces     []string
+	FailOnOrphans         bool
+	Output                string
+	Interval              time.Duration
+	Jitter                time.Duration
+	ListenAddress         string
+	APIKeysFile           string
+	MetricsAddress        string
+	Webhooks              []Webhook
+	WebhookURLs           []string
+	WebhookFormat         string
+	WebhookSecret         string
+	AuditFile             string
 	ClusterName           string
 	ClusterType           string
 	ClusterLabels         string
+	KubernetesVersion     string
+	ClusterAPI            string
+	ProjectAPI            string
+	SkipVersionCheck      bool
 	ClusterStatus         string
 	ClusterID             string
 	ClusterIDs            []string
 	CreateKubeconfig      bool
 	CreateNamespace       bool
 	CreateProject         bool
+	NetworkIsolation      bool
+	PodSecurity           string
+	ProjectTemplate       string
+	ProjectTemplates      map[string]ProjectTemplate
+	CreateNetworkPolicies bool
 	FilterClustersByType  bool
 	FilterClustersByLabel bool
 	KubeconfigFile        string
@@ -27,183 +64,495 @@ type Config struct {
 	RancherAccessKey      string
 	RancherSecretKey      string
 	RancherServerURL      string
+	RancherSecretKeyFile  string
+	RancherToken          string
+	RancherTokenFile      string
+	UseRancherCLI         bool
+	RancherCLIConfig      string
+	RancherCLIServer      string
+	ConfigFile            string
+	Profile               string
+	CAFile                string
+	CAData                string
+	CAChecksum            string
+	BootstrapCACerts      bool
+	ClientCertFile        string
+	ClientKeyFile         string
+	InsecureSkipTLSVerify bool
+	RecordFile            string
+	ReplayFile            string
 	Debug                 bool
 	ShowHelp              bool
+
+	// Caller is the identity changes are made on behalf of, recorded in the audit log. The serve
+	// command sets it to the authenticated API key for each request.
+	Caller string
+}
+
+// DefaultExcludeNamespaces are the system namespaces the orphans command ignores unless
+// --exclude-namespaces is set.
+var DefaultExcludeNamespaces = []string{"default", "kube-*", "cattle-*", "fleet-*", "local"}
+
+// Commands lists the supported commands and their descriptions. The default command, "apply",
+// creates and assigns the configured project and namespace.
+var Commands = map[string]string{
+	"apply":     "Create and assign the configured project and namespace (default)",
+	"drift":     "Report differences between the desired projects and namespaces and each cluster",
+	"daemon":    "Periodically apply the desired projects and namespaces to every selected cluster",
+	"serve":     "Serve the self-service HTTP API for project and namespace requests",
+	"export":    "Write the projects and namespaces of the selected clusters as a manifest to stdout",
+	"snapshot":  "Save the project of every namespace in the selected clusters to --snapshot-file",
+	"restore":   "Move namespaces back to the projects recorded in --snapshot-file",
+	"orphans":   "List namespaces outside any project in the selected clusters",
+	"inventory": "List the selected clusters with their provider, Kubernetes version, nodes and capacity",
+	"check":     "Report namespaces whose Pod Security labels differ from their project's level",
+	"guide":     "Pick a cluster, project and namespace from lists, then assign the namespace and generate a kubeconfig",
+	"assign":    "Assign namespaces to projects according to the --rules file",
+	"replicate": "Copy the projects, members and namespace assignments of --source-cluster to the selected clusters",
 }
 
-var cfg Config
+// Rancher APIs used to discover clusters and manage projects: Norman (/v3) or Steve (/v1). Auto
+// chooses per Rancher version, see --cluster-api and --project-api.
+const (
+	APIAuto   = "auto"
+	APINorman = "norman"
+	APISteve  = "steve"
+)
+
+// Output formats supported by reporting commands.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
 
 var (
 	// Define the currentConfig instance
 	currentConfig = &Config{}
 )
 
+// Init loads the configuration from the command line, the environment and the
+// configuration file, validates it and makes it available through GetConfig.
+// It exits the process when the configuration cannot be loaded or is invalid.
 func Init() *Config {
-	config := &Config{}
-
-	flag.BoolVar(&config.ShowHelp, "h", false, "Show help message")
-	flag.StringVar(&config.ClusterName, "cluster-name", "", "The name of the cluster")
-	flag.BoolVar(&config.CreateKubeconfig, "create-kubeconfig", false, "Generate Kubeconfig")
-	flag.BoolVar(&config.CreateNamespace, "create-namespace", false, "Create a namespace")
-	flag.BoolVar(&config.CreateProject, "create-project", false, "Create a project")
-	flag.BoolVar(&config.FilterClustersByType, "get-clusters-by-type", false, "Get clusters by type")
-	flag.BoolVar(&config.FilterClustersByLabel, "get-clusters-by-label", false, "Get clusters by label")
-	flag.StringVar(&config.KubeconfigFile, "kubeconfig", "", "Kubeconfig file")
-	flag.StringVar(&config.KubeconfigDir, "kubeconfig-dir", "", "Kubeconfig directory")
-	flag.StringVar(&config.Namespace, "namespace", "", "Namespace")
-	flag.StringVar(&config.ProjectName, "project-name", "", "Project name")
-	flag.StringVar(&config.RancherAccessKey, "rancher-access-key", "", "Rancher access key")
-	flag.StringVar(&config.RancherSecretKey, "rancher-secret-key", "", "Rancher secret key")
-	flag.StringVar(&config.RancherServerURL, "rancher-server", "", "Rancher server URL")
-	flag.BoolVar(&config.Debug, "debug", false, "Enable debug mode")
-	flag.Parse()
-
-	// Load additional configuration from environment variables
-	config.LoadConfig()
-
-	// Check for missing required settings (Fix: pass the config instance)
-	checkMissingSettings(config)
+	config, err := Load(os.Args[1:], os.LookupEnv)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
 
-	return config
-}
+	if !config.ShowHelp {
+		if err := config.Validate(); err != nil {
+			fmt.Println("Invalid configuration:")
+			fmt.Println(err)
+			fmt.Println()
+			PrintHelp()
+			os.Exit(1)
+		}
+	}
 
-func (c *Config) LoadConfig() {
-	c.ClusterType = getEnvOrDefault("CLUSTER_TYPE", c.ClusterType)
-	c.ClusterLabels = getEnvOrDefault("CLUSTER_LABELS", c.ClusterLabels)
-	c.ClusterStatus = getEnvOrDefault("CLUSTER_STATUS", c.ClusterStatus)
-	c.ClusterID = getEnvOrDefault("CLUSTER_ID", c.ClusterID)
-	c.ClusterIDs = getEnvArray("CLUSTER_IDS", ",")
-	c.ProjectName = getEnvOrDefault("PROJECT_NAME", c.ProjectName)
-	c.RancherServerURL = getEnvOrDefault("RANCHER_SERVER", c.RancherServerURL)
-	c.RancherAccessKey = getEnvOrDefault("RANCHER_ACCESS_KEY", c.RancherAccessKey)
-	c.RancherSecretKey = getEnvOrDefault("RANCHER_SECRET_KEY", c.RancherSecretKey)
-	c.KubeconfigDir = getEnvOrDefault("KUBECONFIG_DIR", c.KubeconfigDir)
-	c.KubeconfigPrefix = getEnvOrDefault("KUBECONFIG_PREFIX", c.KubeconfigPrefix)
-	c.Namespace = getEnvOrDefault("NAMESPACE", c.Namespace)
-	c.Debug = getEnvBool("DEBUG", c.Debug)
+	currentConfig = config
+	return config
 }
 
-func checkMissingSettings(cfg *Config) {
-	requiredFlags := []string{
-		"rancher-server",
-		"rancher-access-key",
-		"rancher-secret-key",
+// Load builds the configuration from args, the environment and the selected profile
+// of the configuration file. Flags take precedence over environment variables, which
+// take precedence over the profile. lookupEnv is usually os.LookupEnv.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	command := "apply"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+		if _, ok := Commands[command]; !ok {
+			return nil, fmt.Errorf("unknown command %q", command)
+		}
 	}
 
-	var requiredFlagCombos [][]string
-	if cfg.ClusterName == "" {
-		requiredFlagCombos = [][]string{
-			{"get-clusters-by-label", "get-clusters-by-type"},
+	flags := &Config{}
+	fs := newFlagSet(flags)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return &Config{Command: command, ShowHelp: true}, nil
 		}
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
 	}
 
-	missingRequiredFlags := []string{}
-	missingRequiredFlagCombos := [][]string{}
+	config := &Config{
+		Command:           command,
+		Output:            OutputText,
+		ClusterAPI:        APIAuto,
+		ProjectAPI:        APIAuto,
+		Interval:          5 * time.Minute,
+		ListenAddress:     ":8080",
+		ExcludeNamespaces: DefaultExcludeNamespaces,
+	}
 
-	flagSet := make(map[string]bool)
-	flag.Visit(func(f *flag.Flag) {
-		flagSet[f.Name] = true
-	})
+	path, explicitPath := flags.ConfigFile, flags.ConfigFile != ""
+	if !explicitPath {
+		path, explicitPath = lookupEnv("RANCHER_PROJECTS_CONFIG")
+	}
+	if !explicitPath {
+		path = defaultConfigFile(lookupEnv)
+	}
+	profileName := flags.Profile
+	if profileName == "" {
+		profileName, _ = lookupEnv("RANCHER_PROFILE")
+	}
 
-	for _, flagName := range requiredFlags {
-		if !flagSet[flagName] {
-			missingRequiredFlags = append(missingRequiredFlags, "--"+flagName)
+	file, profile, err := loadProfile(path, profileName, explicitPath)
+	if err != nil {
+		return nil, err
+	}
+	if file != nil {
+		config.ProjectTemplates = file.ProjectTemplates
+	}
+	if profile != nil {
+		if err := profile.apply(config, lookupEnv); err != nil {
+			return nil, err
 		}
 	}
+	config.ConfigFile = path
+	config.Profile = profileName
 
-	// Check for missing flag combinations only if required
-	if len(requiredFlagCombos) > 0 {
-		for _, flagCombo := range requiredFlagCombos {
-			if (!flagSet[flagCombo[0]] && !flagSet[flagCombo[1]]) ||
-				(flagSet[flagCombo[0]] && flagSet[flagCombo[1]]) {
-				missingRequiredFlagCombos = append(missingRequiredFlagCombos, flagCombo)
-			}
+	if err := config.loadEnv(lookupEnv); err != nil {
+		return nil, err
+	}
+
+	// Flags explicitly set on the command line win over everything else.
+	overrides := newFlagSet(config)
+	var setErr error
+	fs.Visit(func(f *flag.Flag) {
+		if err := overrides.Set(f.Name, f.Value.String()); err != nil && setErr == nil {
+			setErr = err
 		}
+	})
+	if setErr != nil {
+		return nil, setErr
 	}
 
-	if len(missingRequiredFlags) > 0 || len(missingRequiredFlagCombos) > 0 {
-		fmt.Println("Missing required flags:")
-		if len(missingRequiredFlags) > 0 {
-			fmt.Println("\nSingle flags:")
-			for _, flagName := range missingRequiredFlags {
-				fmt.Println("-", flagName)
-			}
+	if err := config.resolveCredentials(); err != nil {
+		return nil, err
+	}
+	config.resolveWebhooks()
+	return config, nil
+}
+
+// newFlagSet returns the command line flags bound to c, using the current values of c as defaults.
+func newFlagSet(c *Config) *flag.FlagSet {
+	fs := flag.NewFlagSet("rancher-projects", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	fs.BoolVar(&c.ShowHelp, "h", c.ShowHelp, "Show help message")
+	fs.StringVar(&c.ConfigFile, "config", c.ConfigFile, "Path to the configuration file (default ~/.config/rancher-projects/config.yaml)")
+	fs.StringVar(&c.Profile, "profile", c.Profile, "Named profile from the configuration file")
+	fs.StringVar(&c.ManifestFile, "manifest", c.ManifestFile, "Manifest file listing the desired projects and namespaces")
+	fs.StringVar(&c.Output, "output", c.Output, "Report format: text or json")
+	fs.DurationVar(&c.Interval, "interval", c.Interval, "Time between reconcile cycles of the daemon command")
+	fs.DurationVar(&c.Jitter, "jitter", c.Jitter, "Maximum random delay added to each daemon interval")
+	fs.StringVar(&c.ListenAddress, "listen", c.ListenAddress, "Address the serve command listens on")
+	fs.StringVar(&c.APIKeysFile, "api-keys-file", c.APIKeysFile, "File with the API keys accepted by the serve command, one name:key per line")
+	fs.StringVar(&c.MetricsAddress, "metrics-listen", c.MetricsAddress, "Address the daemon command serves Prometheus metrics on (disabled when empty)")
+	fs.Var(&stringList{values: &c.WebhookURLs}, "webhook", "Webhook URL notified about changes and failures (repeatable)")
+	fs.StringVar(&c.WebhookFormat, "webhook-format", c.WebhookFormat, "Payload format for --webhook: json, slack or teams")
+	fs.StringVar(&c.WebhookSecret, "webhook-secret", c.WebhookSecret, "Secret used to sign --webhook payloads with HMAC-SHA256")
+	fs.StringVar(&c.RulesFile, "rules", c.RulesFile, "Rules file mapping namespace patterns and labels to projects, used by assign and daemon")
+	fs.Var(&stringList{values: &c.ExcludeNamespaces}, "exclude-namespaces", "Namespace patterns the orphans command ignores, e.g. kube-* (repeatable, replaces the defaults)")
+	fs.BoolVar(&c.FailOnOrphans, "fail-on-orphans", c.FailOnOrphans, "Exit with code 3 when the orphans command finds any namespace")
+	fs.StringVar(&c.SourceCluster, "source-cluster", c.SourceCluster, "Reference cluster the replicate command copies projects from")
+	fs.StringVar(&c.SnapshotFile, "snapshot-file", c.SnapshotFile, "File the snapshot command writes and the restore command reads")
+	fs.BoolVar(&c.AllowOtherServer, "allow-other-server", c.AllowOtherServer, "Let restore apply a snapshot taken on a different Rancher server")
+	fs.StringVar(&c.AuditFile, "audit-log", c.AuditFile, "Append a JSON line for every change made in Rancher to this file")
+	fs.StringVar(&c.ClusterName, "cluster-name", c.ClusterName, "The name of the cluster")
+	fs.StringVar(&c.ClusterType, "cluster-type", c.ClusterType, "Cluster type used with --get-clusters-by-type (e.g. rke2, k3s)")
+	fs.StringVar(&c.ClusterLabels, "cluster-labels", c.ClusterLabels, "Cluster labels used with --get-clusters-by-label (e.g. env=prod,team=a)")
+	fs.StringVar(&c.KubernetesVersion, "kubernetes-version", c.KubernetesVersion, "Select the clusters whose Kubernetes version is in this range (e.g. <1.28 or >=1.26,<1.28)")
+	fs.StringVar(&c.ClusterAPI, "cluster-api", c.ClusterAPI, "API used to discover clusters: auto, norman (/v3/clusters) or steve (provisioning.cattle.io and management.cattle.io clusters)")
+	fs.StringVar(&c.ProjectAPI, "project-api", c.ProjectAPI, "API used to manage projects: auto, norman (/v3/projects) or steve (management.cattle.io projects)")
+	fs.BoolVar(&c.SkipVersionCheck, "skip-version-check", c.SkipVersionCheck, "Continue when the Rancher server version is not supported")
+	fs.BoolVar(&c.CreateKubeconfig, "create-kubeconfig", c.CreateKubeconfig, "Generate Kubeconfig")
+	fs.BoolVar(&c.CreateNamespace, "create-namespace", c.CreateNamespace, "Create a namespace")
+	fs.BoolVar(&c.CreateProject, "create-project", c.CreateProject, "Create a project")
+	fs.BoolVar(&c.NetworkIsolation, "network-isolation", c.NetworkIsolation, "Enable project network isolation for the projects the tool manages")
+	fs.StringVar(&c.ProjectTemplate, "project-template", c.ProjectTemplate, "Project template from the configuration file applied to the project")
+	fs.StringVar(&c.PodSecurity, "pod-security", c.PodSecurity, "Pod Security level of the project: privileged, baseline or restricted")
+	fs.BoolVar(&c.CreateNetworkPolicies, "create-network-policies", c.CreateNetworkPolicies, "Create default NetworkPolicies in every namespace the tool creates")
+	fs.BoolVar(&c.FilterClustersByType, "get-clusters-by-type", c.FilterClustersByType, "Get clusters by type")
+	fs.BoolVar(&c.FilterClustersByLabel, "get-clusters-by-label", c.FilterClustersByLabel, "Get clusters by label")
+	fs.StringVar(&c.KubeconfigFile, "kubeconfig", c.KubeconfigFile, "Kubeconfig file")
+	fs.StringVar(&c.KubeconfigDir, "kubeconfig-dir", c.KubeconfigDir, "Kubeconfig directory")
+	fs.StringVar(&c.KubeconfigPrefix, "kubeconfig-prefix", c.KubeconfigPrefix, "Prefix for kubeconfig files written to --kubeconfig-dir")
+	fs.StringVar(&c.Namespace, "namespace", c.Namespace, "Namespace")
+	fs.StringVar(&c.ProjectName, "project-name", c.ProjectName, "Project name")
+	fs.StringVar(&c.RancherAccessKey, "rancher-access-key", c.RancherAccessKey, "Rancher access key")
+	fs.StringVar(&c.RancherSecretKey, "rancher-secret-key", c.RancherSecretKey, "Rancher secret key")
+	fs.StringVar(&c.RancherSecretKeyFile, "rancher-secret-key-file", c.RancherSecretKeyFile, "Read the Rancher secret key from a file (- for stdin)")
+	fs.StringVar(&c.RancherToken, "rancher-token", c.RancherToken, "Rancher API bearer token (token-xxxxx:secret)")
+	fs.StringVar(&c.RancherTokenFile, "rancher-token-file", c.RancherTokenFile, "Read the Rancher API bearer token from a file (- for stdin)")
+	fs.BoolVar(&c.UseRancherCLI, "rancher-cli", c.UseRancherCLI, "Import server and credentials from the Rancher CLI config")
+	fs.StringVar(&c.RancherCLIConfig, "rancher-cli-config", c.RancherCLIConfig, "Rancher CLI config file (default ~/.rancher/cli2.json)")
+	fs.StringVar(&c.RancherCLIServer, "rancher-cli-server", c.RancherCLIServer, "Server entry in the Rancher CLI config (default current server)")
+	fs.StringVar(&c.RancherServerURL, "rancher-server", c.RancherServerURL, "Rancher server URL")
+	fs.StringVar(&c.CAFile, "ca-file", c.CAFile, "CA bundle used to verify the Rancher server certificate")
+	fs.BoolVar(&c.InsecureSkipTLSVerify, "insecure-skip-tls-verify", c.InsecureSkipTLSVerify, "Skip verification of the Rancher server certificate (insecure)")
+	fs.StringVar(&c.ClientCertFile, "client-cert", c.ClientCertFile, "Client certificate for TLS client authentication")
+	fs.StringVar(&c.ClientKeyFile, "client-key", c.ClientKeyFile, "Client key for TLS client authentication")
+	fs.BoolVar(&c.BootstrapCACerts, "bootstrap-cacerts", c.BootstrapCACerts, "Trust the CA published in Rancher's cacerts setting")
+	fs.StringVar(&c.CAChecksum, "ca-checksum", c.CAChecksum, "Expected SHA256 checksum of Rancher's cacerts setting, used with --bootstrap-cacerts")
+	fs.StringVar(&c.RecordFile, "record", c.RecordFile, "Record all Rancher API traffic, with credentials scrubbed, to a cassette file")
+	fs.StringVar(&c.ReplayFile, "replay", c.ReplayFile, "Replay Rancher API traffic from a cassette file instead of contacting the server")
+	fs.BoolVar(&c.Debug, "debug", c.Debug, "Enable debug mode")
+	return fs
+}
+
+// loadEnv overrides c with any settings found in the environment.
+func (c *Config) loadEnv(lookupEnv func(string) (string, bool)) error {
+	getEnv := func(key, fallback string) string {
+		if value, exists := lookupEnv(key); exists {
+			return value
 		}
-		if len(missingRequiredFlagCombos) > 0 {
-			fmt.Println("\nFlag combinations:")
-			for _, flagCombo := range missingRequiredFlagCombos {
-				fmt.Printf("- Either %s or %s\n", "--"+flagCombo[0], "--"+flagCombo[1])
+		return fallback
+	}
+
+	c.ClusterName = getEnv("CLUSTER_NAME", c.ClusterName)
+	c.ClusterType = getEnv("CLUSTER_TYPE", c.ClusterType)
+	c.ClusterLabels = getEnv("CLUSTER_LABELS", c.ClusterLabels)
+	c.KubernetesVersion = getEnv("RANCHER_PROJECTS_KUBERNETES_VERSION", c.KubernetesVersion)
+	c.ClusterAPI = getEnv("RANCHER_PROJECTS_CLUSTER_API", c.ClusterAPI)
+	c.ProjectAPI = getEnv("RANCHER_PROJECTS_PROJECT_API", c.ProjectAPI)
+	if value, exists := lookupEnv("RANCHER_PROJECTS_SKIP_VERSION_CHECK"); exists {
+		c.SkipVersionCheck = value == "true" || value == "1"
+	}
+	c.ClusterStatus = getEnv("CLUSTER_STATUS", c.ClusterStatus)
+	c.ClusterID = getEnv("CLUSTER_ID", c.ClusterID)
+	if value, exists := lookupEnv("CLUSTER_IDS"); exists {
+		c.ClusterIDs = strings.Split(value, ",")
+	}
+	c.ProjectName = getEnv("PROJECT_NAME", c.ProjectName)
+	c.RancherServerURL = getEnv("RANCHER_SERVER", c.RancherServerURL)
+	c.RancherAccessKey = getEnv("RANCHER_ACCESS_KEY", c.RancherAccessKey)
+	c.RancherSecretKey = getEnv("RANCHER_SECRET_KEY", c.RancherSecretKey)
+	c.RancherSecretKeyFile = getEnv("RANCHER_SECRET_KEY_FILE", c.RancherSecretKeyFile)
+	c.RancherToken = getEnv("RANCHER_TOKEN", c.RancherToken)
+	c.RancherTokenFile = getEnv("RANCHER_TOKEN_FILE", c.RancherTokenFile)
+	c.RancherCLIServer = getEnv("RANCHER_CLI_SERVER", c.RancherCLIServer)
+	c.KubeconfigFile = getEnv("KUBECONFIG_FILE", c.KubeconfigFile)
+	c.KubeconfigDir = getEnv("KUBECONFIG_DIR", c.KubeconfigDir)
+	c.KubeconfigPrefix = getEnv("KUBECONFIG_PREFIX", c.KubeconfigPrefix)
+	c.Namespace = getEnv("NAMESPACE", c.Namespace)
+	c.ManifestFile = getEnv("RANCHER_PROJECTS_MANIFEST", c.ManifestFile)
+	c.ListenAddress = getEnv("RANCHER_PROJECTS_LISTEN", c.ListenAddress)
+	c.APIKeysFile = getEnv("RANCHER_PROJECTS_API_KEYS_FILE", c.APIKeysFile)
+	c.MetricsAddress = getEnv("RANCHER_PROJECTS_METRICS_LISTEN", c.MetricsAddress)
+	if value, exists := lookupEnv("RANCHER_PROJECTS_WEBHOOKS"); exists {
+		c.WebhookURLs = splitList(value)
+	}
+	c.WebhookFormat = getEnv("RANCHER_PROJECTS_WEBHOOK_FORMAT", c.WebhookFormat)
+	c.WebhookSecret = getEnv("RANCHER_PROJECTS_WEBHOOK_SECRET", c.WebhookSecret)
+	c.AuditFile = getEnv("RANCHER_PROJECTS_AUDIT_LOG", c.AuditFile)
+	c.SnapshotFile = getEnv("RANCHER_PROJECTS_SNAPSHOT_FILE", c.SnapshotFile)
+	c.SourceCluster = getEnv("RANCHER_PROJECTS_SOURCE_CLUSTER", c.SourceCluster)
+	c.RulesFile = getEnv("RANCHER_PROJECTS_RULES", c.RulesFile)
+	if value, exists := lookupEnv("RANCHER_PROJECTS_EXCLUDE_NAMESPACES"); exists {
+		c.ExcludeNamespaces = splitList(value)
+	}
+	if value, exists := lookupEnv("RANCHER_PROJECTS_FAIL_ON_ORPHANS"); exists {
+		c.FailOnOrphans = value == "true" || value == "1"
+	}
+	if value, exists := lookupEnv("RANCHER_PROJECTS_ALLOW_OTHER_SERVER"); exists {
+		c.AllowOtherServer = value == "true" || value == "1"
+	}
+	if value, exists := lookupEnv("RANCHER_PROJECTS_NETWORK_ISOLATION"); exists {
+		c.NetworkIsolation = value == "true" || value == "1"
+	}
+	c.PodSecurity = getEnv("RANCHER_PROJECTS_POD_SECURITY", c.PodSecurity)
+	c.ProjectTemplate = getEnv("RANCHER_PROJECTS_PROJECT_TEMPLATE", c.ProjectTemplate)
+	if value, exists := lookupEnv("RANCHER_PROJECTS_CREATE_NETWORK_POLICIES"); exists {
+		c.CreateNetworkPolicies = value == "true" || value == "1"
+	}
+	for key, target := range map[string]*time.Duration{"RANCHER_PROJECTS_INTERVAL": &c.Interval, "RANCHER_PROJECTS_JITTER": &c.Jitter} {
+		if value, exists := lookupEnv(key); exists {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
 			}
+			*target = d
 		}
-		fmt.Println("\nPlease provide the missing flags.")
-		PrintHelp()
-		os.Exit(1)
 	}
+	c.CAFile = getEnv("RANCHER_CA_FILE", c.CAFile)
+	c.CAChecksum = getEnv("RANCHER_CA_CHECKSUM", c.CAChecksum)
+	c.ClientCertFile = getEnv("RANCHER_CLIENT_CERT", c.ClientCertFile)
+	c.ClientKeyFile = getEnv("RANCHER_CLIENT_KEY", c.ClientKeyFile)
+	c.RecordFile = getEnv("RANCHER_PROJECTS_RECORD", c.RecordFile)
+	c.ReplayFile = getEnv("RANCHER_PROJECTS_REPLAY", c.ReplayFile)
+	if value, exists := lookupEnv("RANCHER_INSECURE_SKIP_TLS_VERIFY"); exists {
+		c.InsecureSkipTLSVerify = value == "true" || value == "1"
+	}
+	if value, exists := lookupEnv("DEBUG"); exists {
+		c.Debug = value == "true" || value == "1"
+	}
+	return nil
 }
 
-// LoadConfig loads configuration from environment variables and command line flags
-func LoadConfig() {
-	cfg.ClusterType = getEnv("CLUSTER_TYPE")
-	cfg.ClusterLabels = getEnv("CLUSTER_LABELS")
-	cfg.ClusterStatus = getEnv("CLUSTER_STATUS")
-	cfg.ClusterID = getEnv("CLUSTER_ID")
-	cfg.ClusterIDs = getEnvArray("CLUSTER_IDS", ",")
-	cfg.ProjectName = getEnv("PROJECT_NAME")
-	cfg.RancherServerURL = getEnv("RANCHER_SERVER")
-	cfg.RancherAccessKey = getEnv("RANCHER_ACCESS_KEY")
-	cfg.RancherSecretKey = getEnv("RANCHER_SECRET_KEY")
-	cfg.KubeconfigDir = getEnv("KUBECONFIG_DIR")
-	cfg.KubeconfigPrefix = getEnv("KUBECONFIG_PREFIX")
-	cfg.Namespace = getEnv("NAMESPACE")
-	cfg.Debug = getEnvBool("DEBUG", false)
-}
+// Validate checks the configuration and reports every problem found at once.
+func (c *Config) Validate() error {
+	var problems []error
 
-// GetConfig returns the current configuration instance
-func GetConfig() *Config {
-	return currentConfig
-}
+	if c.RecordFile != "" && c.ReplayFile != "" {
+		problems = append(problems, errors.New("--record and --replay are mutually exclusive"))
+	}
+	if c.RecordFile != "" && (c.Command == "daemon" || c.Command == "serve") {
+		problems = append(problems, fmt.Errorf("--record cannot be used with %s: the cassette would grow without bound", c.Command))
+	}
 
-// getEnv gets an environment variable.
-// If the variable is not found, it returns an empty string.
-func getEnv(key string) string {
-	value, exists := os.LookupEnv(key)
-	if !exists {
-		if flag.Lookup(key) != nil {
-			return flag.Lookup(key).DefValue
+	if c.RancherServerURL == "" {
+		// A replayed run takes the server from the cassette.
+		if c.ReplayFile == "" {
+			problems = append(problems, errors.New("missing Rancher server: set --rancher-server or RANCHER_SERVER"))
+		}
+	} else if u, err := url.Parse(c.RancherServerURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		problems = append(problems, fmt.Errorf("invalid Rancher server URL %q: must include http:// or https://", c.RancherServerURL))
+	}
+	switch {
+	case c.ReplayFile != "":
+		// A replayed run never authenticates.
+	case c.RancherToken != "":
+		if !strings.HasPrefix(c.RancherToken, "token-") || !strings.Contains(c.RancherToken, ":") {
+			problems = append(problems, errors.New("invalid Rancher token: expected token-xxxxx:secret"))
+		}
+	default:
+		if c.RancherAccessKey == "" {
+			problems = append(problems, errors.New("missing Rancher access key: set --rancher-access-key, RANCHER_ACCESS_KEY or --rancher-token"))
+		}
+		if c.RancherSecretKey == "" {
+			problems = append(problems, errors.New("missing Rancher secret key: set --rancher-secret-key, RANCHER_SECRET_KEY or --rancher-token"))
 		}
 	}
-	return value
-}
 
-// getEnvBool gets an environment variable and returns it as a boolean
-func getEnvBool(key string, fallback bool) bool {
-	value, exists := os.LookupEnv(key)
-	if !exists {
-		return fallback
+	if (c.ClientCertFile == "") != (c.ClientKeyFile == "") {
+		problems = append(problems, errors.New("--client-cert and --client-key must be set together"))
+	}
+	if c.CAChecksum != "" && !c.BootstrapCACerts {
+		problems = append(problems, errors.New("--ca-checksum requires --bootstrap-cacerts"))
+	}
+	if c.BootstrapCACerts && (c.CAFile != "" || c.InsecureSkipTLSVerify) {
+		problems = append(problems, errors.New("--bootstrap-cacerts cannot be combined with --ca-file or --insecure-skip-tls-verify"))
 	}
-	return value == "true" || value == "1"
-}
 
-// getEnvArray gets an environment variable and returns it as an array
-func getEnvArray(key, separator string) []string {
-	value, exists := os.LookupEnv(key)
-	if !exists {
-		return []string{}
+	if c.FilterClustersByType && c.ClusterType == "" {
+		problems = append(problems, errors.New("--get-clusters-by-type requires a cluster type: set --cluster-type or CLUSTER_TYPE"))
+	}
+	if c.FilterClustersByLabel && c.ClusterLabels == "" {
+		problems = append(problems, errors.New("--get-clusters-by-label requires cluster labels: set --cluster-labels or CLUSTER_LABELS"))
+	}
+	if c.ClusterType != "" && c.ClusterLabels != "" {
+		problems = append(problems, errors.New("cluster type and cluster labels are mutually exclusive"))
+	}
+	// The API server receives the cluster with every request; the inventory lists every cluster and
+	// guide asks for one.
+	if c.ClusterName == "" && !c.SelectsClusters() && c.Command != "serve" && c.Command != "inventory" && c.Command != "guide" {
+		problems = append(problems, errors.New("missing cluster selection: set --cluster-name, --cluster-type, --cluster-labels or --kubernetes-version"))
+	}
+	if c.KubernetesVersion != "" {
+		if _, err := kubeversion.ParseRange(c.KubernetesVersion); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	switch c.ClusterAPI {
+	case "", APIAuto, APINorman, APISteve:
+	default:
+		problems = append(problems, fmt.Errorf("invalid cluster API %q: expected auto, norman or steve", c.ClusterAPI))
+	}
+	switch c.ProjectAPI {
+	case "", APIAuto, APINorman, APISteve:
+	default:
+		problems = append(problems, fmt.Errorf("invalid project API %q: expected auto, norman or steve", c.ProjectAPI))
+	}
+	if c.ClusterLabels != "" {
+		for _, keyPair := range strings.Split(c.ClusterLabels, ",") {
+			if parts := strings.Split(keyPair, "="); len(parts) != 2 || parts[0] == "" {
+				problems = append(problems, fmt.Errorf("invalid cluster label %q: expected key=value", keyPair))
+			}
+		}
 	}
-	return strings.Split(value, separator)
-}
 
-func getEnvOrDefault(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+	if c.Output != "" && c.Output != OutputText && c.Output != OutputJSON {
+		problems = append(problems, fmt.Errorf("invalid output format %q: expected text or json", c.Output))
+	}
+	if c.Command == "drift" && c.ManifestFile == "" && c.ProjectName == "" {
+		problems = append(problems, errors.New("drift requires --manifest or --project-name"))
+	}
+	if c.Command == "daemon" && c.ManifestFile == "" && c.ProjectName == "" && c.RulesFile == "" {
+		problems = append(problems, errors.New("daemon requires --manifest, --project-name or --rules"))
+	}
+	if c.Command == "assign" && c.RulesFile == "" {
+		problems = append(problems, errors.New("assign requires --rules"))
 	}
-	return fallback
+	if (c.Command == "snapshot" || c.Command == "restore") && c.SnapshotFile == "" {
+		problems = append(problems, fmt.Errorf("%s requires --snapshot-file", c.Command))
+	}
+	for _, pattern := range c.ExcludeNamespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			problems = append(problems, fmt.Errorf("invalid namespace pattern %q: %v", pattern, err))
+		}
+	}
+	if c.Command == "replicate" && c.SourceCluster == "" {
+		problems = append(problems, errors.New("replicate requires --source-cluster"))
+	}
+	if c.Command == "daemon" && c.Interval <= 0 {
+		problems = append(problems, errors.New("--interval must be positive"))
+	}
+	if err := c.validateWebhooks(); err != nil {
+		problems = append(problems, err)
+	}
+	if err := c.validateProjectTemplates(); err != nil {
+		problems = append(problems, err)
+	}
+	if c.Command == "serve" && c.APIKeysFile == "" {
+		problems = append(problems, errors.New("serve requires --api-keys-file"))
+	}
+	if c.Jitter < 0 {
+		problems = append(problems, errors.New("--jitter must not be negative"))
+	}
+
+	if c.CreateProject && c.ProjectName == "" {
+		problems = append(problems, errors.New("--create-project requires --project-name"))
+	}
+	if c.CreateNamespace && c.Namespace == "" {
+		problems = append(problems, errors.New("--create-namespace requires --namespace"))
+	}
+	if c.PodSecurity != "" {
+		if !podsecurity.Valid(c.PodSecurity) {
+			problems = append(problems, fmt.Errorf("invalid Pod Security level %q: expected %s", c.PodSecurity, strings.Join(podsecurity.Levels, ", ")))
+		} else if c.ProjectName == "" {
+			problems = append(problems, errors.New("--pod-security requires --project-name"))
+		}
+	}
+	if c.CreateKubeconfig && c.KubeconfigFile == "" && c.KubeconfigDir == "" {
+		problems = append(problems, errors.New("--create-kubeconfig requires --kubeconfig or --kubeconfig-dir"))
+	}
+
+	return errors.Join(problems...)
+}
+
+// GetConfig returns the current configuration instance
+func GetConfig() *Config {
+	return currentConfig
 }
 
 func PrintHelp() {
-	fmt.Println("Usage: rancher-projects [options]")
+	fmt.Println("Usage: rancher-projects [command] [options]")
+	fmt.Println("Commands:")
+	names := make([]string, 0, len(Commands))
+	for name := range Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %s\t%s\n", name, Commands[name])
+	}
 	fmt.Println("Options:")
-	flag.VisitAll(func(f *flag.Flag) {
+	newFlagSet(&Config{}).VisitAll(func(f *flag.Flag) {
 		fmt.Printf("  --%s %s\n", f.Name, f.Usage)
 	})
 
@@ -217,41 +566,57 @@ func PrintHelp() {
 	fmt.Println("    --cluster-name \"MyCluster\" \\")
 	fmt.Println("    --project-name \"MyProject\" \\")
 	fmt.Println("    --namespace \"mynamespace\" \\")
-	fmt.Println("    --create-project true \\")
-	fmt.Println("    --create-namespace true \\")
-	fmt.Println("    --create-kubeconfig true \\")
+	fmt.Println("    --create-project \\")
+	fmt.Println("    --create-namespace \\")
+	fmt.Println("    --create-kubeconfig \\")
 	fmt.Println("    --kubeconfig \"rancher-projects-kubeconfig\"")
 	fmt.Println("\n  Getting a kubeconfig for multiple RKE2 clusters:")
 	fmt.Println("    rancher-projects \\")
 	fmt.Println("    --rancher-server \"https://rancher.mattox.local\" \\")
 	fmt.Println("    --rancher-access-key \"token-abcde\" \\")
 	fmt.Println("    --rancher-secret-key \"123456789abcdefghijklmnopqrstuvwxyz\" \\")
-	fmt.Println("    --create-kubeconfig true \\")
-	fmt.Println("    --kubeconfig-dir \"~/.kube/\"")
-	fmt.Println("    --get-clusters-by-type \"rke2\"")
-
+	fmt.Println("    --create-kubeconfig \\")
+	fmt.Println("    --kubeconfig-dir \"~/.kube/\" \\")
+	fmt.Println("    --get-clusters-by-type \\")
+	fmt.Println("    --cluster-type \"rke2\"")
+	fmt.Println("\n  Reporting drift from a manifest across all production clusters:")
+	fmt.Println("    rancher-projects drift \\")
+	fmt.Println("    --profile prod \\")
+	fmt.Println("    --cluster-labels \"env=prod\" \\")
+	fmt.Println("    --manifest projects.yaml")
+	fmt.Println("\n  Applying a manifest to all RKE2 clusters every 10 minutes:")
+	fmt.Println("    rancher-projects daemon \\")
+	fmt.Println("    --profile prod \\")
+	fmt.Println("    --cluster-type \"rke2\" \\")
+	fmt.Println("    --manifest projects.yaml \\")
+	fmt.Println("    --interval 10m \\")
+	fmt.Println("    --jitter 1m")
 }
 
 // PrintConfig prints the loaded configuration
-func PrintConfig() {
+func (c *Config) PrintConfig() {
 	fmt.Println("Loaded Configuration:")
-	fmt.Println("Show Help:", cfg.ShowHelp)
-	fmt.Println("Cluster Name:", cfg.ClusterName)
-	fmt.Println("Create Kubeconfig:", cfg.CreateKubeconfig)
-	fmt.Println("Create Namespace:", cfg.CreateNamespace)
-	fmt.Println("Create Project:", cfg.CreateProject)
-	fmt.Println("Filter Clusters by Type:", cfg.FilterClustersByType)
-	fmt.Println("Filter Clusters by Label:", cfg.FilterClustersByLabel)
-	fmt.Println("Kubeconfig File:", cfg.KubeconfigFile)
-	fmt.Println("Kubeconfig Dir:", cfg.KubeconfigDir)
-	fmt.Println("Kubeconfig Prefix:", cfg.KubeconfigPrefix)
-	fmt.Println("Rancher Server URL:", cfg.RancherServerURL)
-	fmt.Println("Rancher Access Key:", cfg.RancherAccessKey)
+	fmt.Println("Command:", c.Command)
+	fmt.Println("Manifest File:", c.ManifestFile)
+	fmt.Println("Config File:", c.ConfigFile)
+	fmt.Println("Profile:", c.Profile)
+	fmt.Println("Cluster Name:", c.ClusterName)
+	fmt.Println("Create Kubeconfig:", c.CreateKubeconfig)
+	fmt.Println("Create Namespace:", c.CreateNamespace)
+	fmt.Println("Create Project:", c.CreateProject)
+	fmt.Println("Filter Clusters by Type:", c.FilterClustersByType)
+	fmt.Println("Filter Clusters by Label:", c.FilterClustersByLabel)
+	fmt.Println("Kubeconfig File:", c.KubeconfigFile)
+	fmt.Println("Kubeconfig Dir:", c.KubeconfigDir)
+	fmt.Println("Kubeconfig Prefix:", c.KubeconfigPrefix)
+	fmt.Println("Rancher Server URL:", c.RancherServerURL)
+	fmt.Println("Rancher Access Key:", c.RancherAccessKey)
 }
 
-// ParseFlags parses command line flags
-func ParseFlags() {
-	flag.Parse()
+// SelectsClusters reports whether the clusters are selected by type, labels or Kubernetes version
+// rather than by --cluster-name.
+func (c *Config) SelectsClusters() bool {
+	return c.ClusterType != "" || c.ClusterLabels != "" || c.KubernetesVersion != ""
 }
 
 func (c *Config) GetClusterType() string {
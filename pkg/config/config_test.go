@@ -0,0 +1,505 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// envMap returns a lookup function backed by the given map.
+func envMap(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+const testConfigFile = `
+defaultProfile: prod
+profiles:
+  prod:
+    server: https://file.example.com
+    credentials:
+      accessKey: file-access
+      secretKey: file-secret
+    tls:
+      caFile: /etc/ssl/prod-ca.pem
+    defaults:
+      clusterName: file-cluster
+      projectName: file-project
+      namespace: file-namespace
+      kubeconfigPrefix: file-
+  lab:
+    server: https://lab.example.com
+    credentials:
+      accessKeyEnv: LAB_ACCESS_KEY
+      secretKeyEnv: LAB_SECRET_KEY
+    tls:
+      insecureSkipVerify: true
+`
+
+func TestLoadPrecedence(t *testing.T) {
+	// Every combination of flag, environment and file for the same setting.
+	tests := []struct {
+		name     string
+		flag     bool
+		env      bool
+		file     bool
+		expected string
+	}{
+		{"none", false, false, false, ""},
+		{"file only", false, false, true, "file-cluster"},
+		{"env only", false, true, false, "env-cluster"},
+		{"env over file", false, true, true, "env-cluster"},
+		{"flag only", true, false, false, "flag-cluster"},
+		{"flag over file", true, false, true, "flag-cluster"},
+		{"flag over env", true, true, false, "flag-cluster"},
+		{"flag over env over file", true, true, true, "flag-cluster"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := map[string]string{"XDG_CONFIG_HOME": t.TempDir()}
+			var args []string
+			if test.file {
+				args = append(args, "--config", writeConfigFile(t, testConfigFile))
+			}
+			if test.env {
+				env["CLUSTER_NAME"] = "env-cluster"
+			}
+			if test.flag {
+				args = append(args, "--cluster-name", "flag-cluster")
+			}
+
+			cfg, err := Load(args, envMap(env))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, cfg.ClusterName)
+		})
+	}
+}
+
+func TestLoadMergesSources(t *testing.T) {
+	path := writeConfigFile(t, testConfigFile)
+	env := map[string]string{
+		"RANCHER_PROJECTS_CONFIG": path,
+		"RANCHER_SECRET_KEY":      "env-secret",
+		"NAMESPACE":               "env-namespace",
+		"CLUSTER_IDS":             "a:c-1,b:c-2",
+	}
+	args := []string{"--project-name", "flag-project", "--kubeconfig-prefix", "flag-", "--create-project"}
+
+	cfg, err := Load(args, envMap(env))
+	require.NoError(t, err)
+
+	assert.Equal(t, path, cfg.ConfigFile)
+	assert.Equal(t, "https://file.example.com", cfg.RancherServerURL)
+	assert.Equal(t, "file-access", cfg.RancherAccessKey)
+	assert.Equal(t, "env-secret", cfg.RancherSecretKey)
+	assert.Equal(t, "/etc/ssl/prod-ca.pem", cfg.CAFile)
+	assert.Equal(t, "file-cluster", cfg.ClusterName)
+	assert.Equal(t, "flag-project", cfg.ProjectName)
+	assert.Equal(t, "env-namespace", cfg.Namespace)
+	assert.Equal(t, "flag-", cfg.KubeconfigPrefix)
+	assert.Equal(t, []string{"a:c-1", "b:c-2"}, cfg.ClusterIDs)
+	assert.True(t, cfg.CreateProject)
+}
+
+func TestLoadProfileSelection(t *testing.T) {
+	path := writeConfigFile(t, testConfigFile)
+	env := map[string]string{
+		"LAB_ACCESS_KEY": "lab-access",
+		"LAB_SECRET_KEY": "lab-secret",
+	}
+
+	t.Run("flag", func(t *testing.T) {
+		cfg, err := Load([]string{"--config", path, "--profile", "lab"}, envMap(env))
+		require.NoError(t, err)
+		assert.Equal(t, "lab", cfg.Profile)
+		assert.Equal(t, "https://lab.example.com", cfg.RancherServerURL)
+		assert.Equal(t, "lab-access", cfg.RancherAccessKey)
+		assert.Equal(t, "lab-secret", cfg.RancherSecretKey)
+		assert.True(t, cfg.InsecureSkipTLSVerify)
+		assert.Empty(t, cfg.ClusterName)
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		env := map[string]string{"RANCHER_PROFILE": "lab"}
+		cfg, err := Load([]string{"--config", path}, envMap(env))
+		require.NoError(t, err)
+		assert.Equal(t, "https://lab.example.com", cfg.RancherServerURL)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := Load([]string{"--config", path, "--profile", "missing"}, envMap(env))
+		assert.ErrorContains(t, err, `profile "missing" not found`)
+	})
+
+	t.Run("missing explicit file", func(t *testing.T) {
+		_, err := Load([]string{"--config", filepath.Join(t.TempDir(), "nope.yaml")}, envMap(env))
+		assert.Error(t, err)
+	})
+
+	t.Run("missing default file", func(t *testing.T) {
+		env := map[string]string{"XDG_CONFIG_HOME": t.TempDir()}
+		cfg, err := Load(nil, envMap(env))
+		require.NoError(t, err)
+		assert.Empty(t, cfg.RancherServerURL)
+
+		_, err = Load([]string{"--profile", "prod"}, envMap(env))
+		assert.Error(t, err)
+	})
+
+	t.Run("secret key file", func(t *testing.T) {
+		secretFile := filepath.Join(t.TempDir(), "secret")
+		require.NoError(t, os.WriteFile(secretFile, []byte("file-secret\n"), 0o600))
+		path := writeConfigFile(t, "profiles:\n  p:\n    credentials:\n      secretKeyFile: "+secretFile+"\n")
+		cfg, err := Load([]string{"--config", path, "--profile", "p"}, envMap(nil))
+		require.NoError(t, err)
+		assert.Equal(t, "file-secret", cfg.RancherSecretKey)
+	})
+}
+
+func TestLoadHelp(t *testing.T) {
+	for _, args := range [][]string{{"-h"}, {"--help"}} {
+		cfg, err := Load(args, envMap(map[string]string{"XDG_CONFIG_HOME": t.TempDir()}))
+		require.NoError(t, err)
+		assert.True(t, cfg.ShowHelp)
+	}
+
+	_, err := Load([]string{"--no-such-flag"}, envMap(nil))
+	assert.Error(t, err)
+}
+
+func TestLoadCommand(t *testing.T) {
+	env := envMap(map[string]string{"XDG_CONFIG_HOME": t.TempDir()})
+
+	cfg, err := Load([]string{"--cluster-name", "a"}, env)
+	require.NoError(t, err)
+	assert.Equal(t, "apply", cfg.Command)
+	assert.Equal(t, OutputText, cfg.Output)
+
+	cfg, err = Load([]string{"drift", "--manifest", "projects.yaml", "--output", "json"}, env)
+	require.NoError(t, err)
+	assert.Equal(t, "drift", cfg.Command)
+	assert.Equal(t, "projects.yaml", cfg.ManifestFile)
+	assert.Equal(t, OutputJSON, cfg.Output)
+
+	cfg, err = Load([]string{"daemon", "--jitter", "30s"}, envMap(map[string]string{"XDG_CONFIG_HOME": t.TempDir(), "RANCHER_PROJECTS_INTERVAL": "1m"}))
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, cfg.Interval)
+	assert.Equal(t, 30*time.Second, cfg.Jitter)
+
+	_, err = Load(nil, envMap(map[string]string{"XDG_CONFIG_HOME": t.TempDir(), "RANCHER_PROJECTS_INTERVAL": "soon"}))
+	assert.ErrorContains(t, err, "invalid RANCHER_PROJECTS_INTERVAL")
+
+	_, err = Load([]string{"frobnicate"}, env)
+	assert.ErrorContains(t, err, `unknown command "frobnicate"`)
+
+	_, err = Load([]string{"drift", "--debug", "extra"}, env)
+	assert.ErrorContains(t, err, `unexpected argument "extra"`)
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{
+			RancherServerURL: "https://rancher.example.com",
+			RancherAccessKey: "token-abcde",
+			RancherSecretKey: "secret",
+			ClusterName:      "cluster",
+		}
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(c *Config)
+		problems []string
+	}{
+		{"valid", func(c *Config) {}, nil},
+		{"env only credentials", func(c *Config) {
+			cfg, err := Load(nil, envMap(map[string]string{
+				"XDG_CONFIG_HOME":    t.TempDir(),
+				"RANCHER_SERVER":     "https://rancher.example.com",
+				"RANCHER_ACCESS_KEY": "token-abcde",
+				"RANCHER_SECRET_KEY": "secret",
+				"CLUSTER_NAME":       "cluster",
+			}))
+			require.NoError(t, err)
+			*c = *cfg
+		}, nil},
+		{"missing credentials", func(c *Config) {
+			c.RancherServerURL, c.RancherAccessKey, c.RancherSecretKey = "", "", ""
+		}, []string{"missing Rancher server", "missing Rancher access key", "missing Rancher secret key"}},
+		{"invalid server url", func(c *Config) { c.RancherServerURL = "rancher.example.com" }, []string{"invalid Rancher server URL"}},
+		{"no cluster selection", func(c *Config) { c.ClusterName = "" }, []string{"missing cluster selection"}},
+		{"cluster type", func(c *Config) { c.ClusterName, c.ClusterType = "", "rke2" }, nil},
+		{"kubernetes version", func(c *Config) { c.ClusterName, c.KubernetesVersion = "", ">=1.26,<1.28" }, nil},
+		{"invalid kubernetes version", func(c *Config) { c.KubernetesVersion = "~1.27" }, []string{`invalid version range "~1.27"`}},
+		{"invalid cluster api", func(c *Config) { c.ClusterAPI = "v3" }, []string{`invalid cluster API "v3"`}},
+		{"invalid project api", func(c *Config) { c.ProjectAPI = "crd" }, []string{`invalid project API "crd"`}},
+		{"inventory without cluster selection", func(c *Config) { c.Command, c.ClusterName = "inventory", "" }, nil},
+		{"guide without cluster selection", func(c *Config) { c.Command, c.ClusterName = "guide", "" }, nil},
+		{"filter flags without values", func(c *Config) {
+			c.FilterClustersByType, c.FilterClustersByLabel = true, true
+		}, []string{"--get-clusters-by-type requires", "--get-clusters-by-label requires"}},
+		{"type and labels", func(c *Config) {
+			c.ClusterType, c.ClusterLabels = "rke2", "env=prod"
+		}, []string{"mutually exclusive"}},
+		{"invalid labels", func(c *Config) { c.ClusterLabels = "env=prod,broken" }, []string{`invalid cluster label "broken"`}},
+		{"client cert without key", func(c *Config) { c.ClientCertFile = "client.crt" }, []string{"--client-cert and --client-key"}},
+		{"checksum without bootstrap", func(c *Config) { c.CAChecksum = "abc" }, []string{"--ca-checksum requires"}},
+		{"bootstrap with insecure", func(c *Config) {
+			c.BootstrapCACerts, c.InsecureSkipTLSVerify = true, true
+		}, []string{"--bootstrap-cacerts cannot be combined"}},
+		{"record and replay", func(c *Config) {
+			c.RecordFile, c.ReplayFile = "a.json", "b.json"
+		}, []string{"--record and --replay are mutually exclusive"}},
+		{"record daemon", func(c *Config) {
+			c.Command, c.RecordFile, c.ProjectName, c.Interval = "daemon", "a.jsonl", "ClusterServices", time.Minute
+		}, []string{"--record cannot be used with daemon"}},
+		{"replay without server or credentials", func(c *Config) {
+			c.RancherServerURL, c.RancherAccessKey, c.RancherSecretKey, c.ReplayFile = "", "", "", "a.json"
+		}, nil},
+		{"invalid output", func(c *Config) { c.Output = "xml" }, []string{`invalid output format "xml"`}},
+		{"drift without desired state", func(c *Config) { c.Command = "drift" }, []string{"drift requires --manifest or --project-name"}},
+		{"restore without snapshot file", func(c *Config) { c.Command = "restore" }, []string{"restore requires --snapshot-file"}},
+		{"snapshot", func(c *Config) { c.Command, c.SnapshotFile = "snapshot", "snapshot.json" }, nil},
+		{"assign without rules", func(c *Config) { c.Command = "assign" }, []string{"assign requires --rules"}},
+		{"daemon with rules only", func(c *Config) {
+			c.Command, c.RulesFile, c.Interval = "daemon", "rules.yaml", time.Minute
+		}, nil},
+		{"replicate without source", func(c *Config) { c.Command = "replicate" }, []string{"replicate requires --source-cluster"}},
+		{"daemon", func(c *Config) {
+			c.Command, c.ManifestFile, c.Interval, c.Jitter = "daemon", "projects.yaml", time.Minute, time.Second
+		}, nil},
+		{"daemon without interval", func(c *Config) {
+			c.Command, c.Interval, c.Jitter = "daemon", 0, -time.Second
+		}, []string{"daemon requires --manifest, --project-name or --rules", "--interval must be positive", "--jitter must not be negative"}},
+		{"serve", func(c *Config) { c.Command, c.ClusterName, c.APIKeysFile = "serve", "", "keys" }, nil},
+		{"serve without api keys", func(c *Config) { c.Command = "serve" }, []string{"serve requires --api-keys-file"}},
+		{"create without names", func(c *Config) {
+			c.CreateProject, c.CreateNamespace, c.CreateKubeconfig = true, true, true
+		}, []string{"--create-project requires", "--create-namespace requires", "--create-kubeconfig requires"}},
+		{"pod security without project", func(c *Config) { c.PodSecurity = "restricted" }, []string{"--pod-security requires --project-name"}},
+		{"invalid pod security", func(c *Config) {
+			c.PodSecurity, c.ProjectName = "strict", "Team"
+		}, []string{`invalid Pod Security level "strict": expected privileged, baseline, restricted`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := valid()
+			test.modify(cfg)
+			err := cfg.Validate()
+			if len(test.problems) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Len(t, strings.Split(err.Error(), "\n"), len(test.problems))
+			for _, problem := range test.problems {
+				assert.Contains(t, err.Error(), problem)
+			}
+		})
+	}
+}
+
+func TestLoadCredentialSources(t *testing.T) {
+	dir := t.TempDir()
+	env := map[string]string{"XDG_CONFIG_HOME": dir}
+
+	t.Run("token file", func(t *testing.T) {
+		tokenFile := filepath.Join(dir, "token")
+		require.NoError(t, os.WriteFile(tokenFile, []byte("token-abcde:secret\n"), 0o600))
+		cfg, err := Load([]string{"--rancher-token-file", tokenFile}, envMap(env))
+		require.NoError(t, err)
+		assert.Equal(t, "token-abcde:secret", cfg.RancherToken)
+	})
+
+	t.Run("secret key from stdin", func(t *testing.T) {
+		stdin = strings.NewReader("stdin-secret\n")
+		defer func() { stdin = os.Stdin }()
+		cfg, err := Load([]string{"--rancher-secret-key-file", "-"}, envMap(env))
+		require.NoError(t, err)
+		assert.Equal(t, "stdin-secret", cfg.RancherSecretKey)
+	})
+
+	t.Run("stdin used twice", func(t *testing.T) {
+		stdin = strings.NewReader("secret")
+		defer func() { stdin = os.Stdin }()
+		_, err := Load([]string{"--rancher-secret-key-file", "-", "--rancher-token-file", "-"}, envMap(env))
+		assert.ErrorContains(t, err, "only one secret")
+	})
+
+	cliConfig := filepath.Join(dir, "cli2.json")
+	require.NoError(t, os.WriteFile(cliConfig, []byte(`{
+		"Servers": {
+			"rancherDefault": {"tokenKey": "token-cli:secret", "url": "https://cli.example.com/"},
+			"other": {"accessKey": "token-other", "secretKey": "other-secret", "url": "https://other.example.com"}
+		},
+		"CurrentServer": "rancherDefault"
+	}`), 0o600))
+
+	t.Run("rancher cli current server", func(t *testing.T) {
+		cfg, err := Load([]string{"--rancher-cli", "--rancher-cli-config", cliConfig}, envMap(env))
+		require.NoError(t, err)
+		assert.Equal(t, "https://cli.example.com", cfg.RancherServerURL)
+		assert.Equal(t, "token-cli:secret", cfg.RancherToken)
+	})
+
+	t.Run("rancher cli named server", func(t *testing.T) {
+		cfg, err := Load([]string{"--rancher-cli", "--rancher-cli-config", cliConfig, "--rancher-cli-server", "other"}, envMap(env))
+		require.NoError(t, err)
+		assert.Equal(t, "https://other.example.com", cfg.RancherServerURL)
+		assert.Equal(t, "token-other", cfg.RancherAccessKey)
+		assert.Equal(t, "other-secret", cfg.RancherSecretKey)
+	})
+
+	t.Run("rancher cli keeps explicit settings", func(t *testing.T) {
+		env := map[string]string{"XDG_CONFIG_HOME": dir, "RANCHER_SERVER": "https://cli.example.com/", "RANCHER_TOKEN": "token-env:secret"}
+		cfg, err := Load([]string{"--rancher-cli", "--rancher-cli-config", cliConfig}, envMap(env))
+		require.NoError(t, err)
+		assert.Equal(t, "https://cli.example.com/", cfg.RancherServerURL)
+		assert.Equal(t, "token-env:secret", cfg.RancherToken)
+	})
+
+	t.Run("rancher cli credentials for another server", func(t *testing.T) {
+		env := map[string]string{"XDG_CONFIG_HOME": dir, "RANCHER_SERVER": "https://env.example.com"}
+		_, err := Load([]string{"--rancher-cli", "--rancher-cli-config", cliConfig}, envMap(env))
+		assert.ErrorContains(t, err, "does not match the Rancher server https://env.example.com")
+
+		env["RANCHER_SERVER"] = "https://other.example.com/"
+		cfg, err := Load([]string{"--rancher-cli", "--rancher-cli-config", cliConfig, "--rancher-cli-server", "other"}, envMap(env))
+		require.NoError(t, err)
+		assert.Equal(t, "token-other", cfg.RancherAccessKey)
+	})
+
+	t.Run("rancher cli unknown server", func(t *testing.T) {
+		_, err := Load([]string{"--rancher-cli", "--rancher-cli-config", cliConfig, "--rancher-cli-server", "nope"}, envMap(env))
+		assert.ErrorContains(t, err, `server "nope" not found`)
+	})
+}
+
+func TestValidateToken(t *testing.T) {
+	cfg := &Config{RancherServerURL: "https://rancher.example.com", RancherToken: "token-abcde:secret", ClusterName: "cluster"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.RancherToken = "not-a-token"
+	assert.ErrorContains(t, cfg.Validate(), "invalid Rancher token")
+}
+
+func TestLoadWebhooks(t *testing.T) {
+	path := writeConfigFile(t, `
+profiles:
+  prod:
+    server: https://file.example.com
+    webhooks:
+      - url: https://hooks.example.com/slack
+        format: slack
+      - url: https://hooks.example.com/signed
+        secretEnv: HOOK_SECRET
+`)
+	env := map[string]string{
+		"RANCHER_PROJECTS_CONFIG":         path,
+		"HOOK_SECRET":                     "profile-secret",
+		"RANCHER_PROJECTS_WEBHOOKS":       "https://env.example.com/a,https://env.example.com/b",
+		"RANCHER_PROJECTS_WEBHOOK_SECRET": "env-secret",
+	}
+
+	cfg, err := Load([]string{"--profile", "prod"}, envMap(env))
+	require.NoError(t, err)
+	assert.Equal(t, []Webhook{
+		{URL: "https://hooks.example.com/slack", Format: "slack"},
+		{URL: "https://hooks.example.com/signed", Secret: "profile-secret"},
+		{URL: "https://env.example.com/a", Secret: "env-secret"},
+		{URL: "https://env.example.com/b", Secret: "env-secret"},
+	}, cfg.Webhooks)
+
+	// Flags replace the environment and can be repeated.
+	cfg, err = Load([]string{"--profile", "prod", "--webhook", "https://flag.example.com/1", "--webhook", "https://flag.example.com/2", "--webhook-format", "teams"}, envMap(env))
+	require.NoError(t, err)
+	require.Len(t, cfg.Webhooks, 4)
+	assert.Equal(t, Webhook{URL: "https://flag.example.com/1", Format: "teams", Secret: "env-secret"}, cfg.Webhooks[2])
+	assert.Equal(t, "https://flag.example.com/2", cfg.Webhooks[3].URL)
+
+	cfg = &Config{Webhooks: []Webhook{{URL: "ftp://example.com"}, {URL: "https://example.com", Format: "xml"}}}
+	err = cfg.validateWebhooks()
+	assert.ErrorContains(t, err, `invalid webhook URL "ftp://example.com"`)
+	assert.ErrorContains(t, err, `invalid webhook format "xml"`)
+}
+
+func TestLoadExcludeNamespaces(t *testing.T) {
+	env := map[string]string{"XDG_CONFIG_HOME": t.TempDir()}
+
+	cfg, err := Load([]string{"orphans"}, envMap(env))
+	require.NoError(t, err)
+	assert.Equal(t, DefaultExcludeNamespaces, cfg.ExcludeNamespaces)
+
+	env["RANCHER_PROJECTS_EXCLUDE_NAMESPACES"] = "kube-*,istio-system"
+	cfg, err = Load([]string{"orphans"}, envMap(env))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kube-*", "istio-system"}, cfg.ExcludeNamespaces)
+
+	cfg, err = Load([]string{"orphans", "--exclude-namespaces", "a-*", "--exclude-namespaces", "b-*,c-*", "--fail-on-orphans"}, envMap(env))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a-*", "b-*", "c-*"}, cfg.ExcludeNamespaces)
+	assert.True(t, cfg.FailOnOrphans)
+	assert.Equal(t, []string{"default", "kube-*", "cattle-*", "fleet-*", "local"}, DefaultExcludeNamespaces, "defaults are not modified")
+
+	cfg = &Config{ClusterName: "c", ExcludeNamespaces: []string{"[a-"}}
+	assert.ErrorContains(t, cfg.Validate(), `invalid namespace pattern "[a-"`)
+}
+
+func TestLoadProjectTemplates(t *testing.T) {
+	path := writeConfigFile(t, `
+projectTemplates:
+  team:
+    description: Team project
+    podSecurity: restricted
+    resourceQuota:
+      limit:
+        pods: "200"
+    members:
+      - role: project-owner
+        group: github_team://1234
+    namespaces: [ci]
+  broken:
+    podSecurity: strict
+    members:
+      - role: project-member
+`)
+	env := map[string]string{"RANCHER_PROJECTS_CONFIG": path, "RANCHER_PROJECTS_PROJECT_TEMPLATE": "team"}
+
+	cfg, err := Load([]string{"--project-name", "Team"}, envMap(env))
+	require.NoError(t, err)
+	assert.Equal(t, "team", cfg.ProjectTemplate)
+	assert.Equal(t, ProjectTemplate{
+		Description:   "Team project",
+		PodSecurity:   "restricted",
+		ResourceQuota: map[string]interface{}{"limit": map[string]interface{}{"pods": "200"}},
+		Members:       []TemplateMember{{Role: "project-owner", Group: "github_team://1234"}},
+		Namespaces:    []string{"ci"},
+	}, cfg.ProjectTemplates["team"])
+
+	cfg.RancherServerURL, cfg.RancherToken, cfg.ClusterName = "https://rancher.example.com", "token-abcde:secret", "cluster"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Equal(t, `project template broken: invalid Pod Security level "strict": expected privileged, baseline, restricted
+project template broken: member 1 needs a role and either a user or a group`, err.Error())
+
+	cfg, err = Load([]string{"--project-template", "missing"}, envMap(env))
+	require.NoError(t, err)
+	assert.ErrorContains(t, cfg.validateProjectTemplates(), `unknown project template "missing": the configuration file defines ["broken" "team"]`)
+	assert.ErrorContains(t, cfg.validateProjectTemplates(), "--project-template requires --project-name")
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stdin is the reader used for secrets passed as "-"; replaced in tests.
+var stdin io.Reader = os.Stdin
+
+// RancherCLIConfig is the subset of the Rancher CLI configuration (~/.rancher/cli2.json) used to import credentials.
+type RancherCLIConfig struct {
+	Servers       map[string]RancherCLIServer `json:"Servers"`
+	CurrentServer string                      `json:"CurrentServer"`
+}
+
+// RancherCLIServer is a single server entry of the Rancher CLI configuration.
+type RancherCLIServer struct {
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"`
+	TokenKey  string `json:"tokenKey"`
+	URL       string `json:"url"`
+	CACert    string `json:"cacert"`
+}
+
+// DefaultRancherCLIConfig returns the default location of the Rancher CLI configuration file.
+func DefaultRancherCLIConfig() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".rancher", "cli2.json")
+}
+
+// ReadRancherCLIConfig reads the Rancher CLI configuration file at path.
+func ReadRancherCLIConfig(path string) (*RancherCLIConfig, error) {
+	data, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Rancher CLI config %s: %w", path, err)
+	}
+
+	var cliConfig RancherCLIConfig
+	if err := json.Unmarshal(data, &cliConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse Rancher CLI config %s: %w", path, err)
+	}
+	return &cliConfig, nil
+}
+
+// Server returns the named server entry, or the CLI's current server when name is empty.
+func (rc *RancherCLIConfig) Server(name string) (*RancherCLIServer, error) {
+	if name == "" {
+		name = rc.CurrentServer
+	}
+	server, ok := rc.Servers[name]
+	if !ok {
+		return nil, fmt.Errorf("server %q not found in Rancher CLI config", name)
+	}
+	return &server, nil
+}
+
+// resolveCredentials fills in credentials that are given by reference: secret files,
+// stdin and the Rancher CLI configuration.
+func (c *Config) resolveCredentials() error {
+	stdinUsed := false
+	read := func(path string) (string, error) {
+		if path == "-" {
+			if stdinUsed {
+				return "", errors.New("only one secret can be read from stdin")
+			}
+			stdinUsed = true
+		}
+		return readSecret(path)
+	}
+
+	if c.RancherSecretKeyFile != "" {
+		secret, err := read(c.RancherSecretKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read secret key: %w", err)
+		}
+		c.RancherSecretKey = secret
+	}
+	if c.RancherTokenFile != "" {
+		token, err := read(c.RancherTokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read token: %w", err)
+		}
+		c.RancherToken = token
+	}
+
+	if c.UseRancherCLI {
+		path := c.RancherCLIConfig
+		if path == "" {
+			path = DefaultRancherCLIConfig()
+		}
+		cliConfig, err := ReadRancherCLIConfig(path)
+		if err != nil {
+			return err
+		}
+		server, err := cliConfig.Server(c.RancherCLIServer)
+		if err != nil {
+			return err
+		}
+		if err := c.importRancherCLIServer(server); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importRancherCLIServer copies the server entry into c. Settings already provided by
+// flags, the environment or a profile are kept. When a Rancher server is already set, the entry
+// must be for the same server so its credentials are never sent to another host.
+func (c *Config) importRancherCLIServer(server *RancherCLIServer) error {
+	serverURL := strings.TrimSuffix(server.URL, "/")
+	switch {
+	case c.RancherServerURL == "":
+		c.RancherServerURL = serverURL
+	case strings.TrimSuffix(c.RancherServerURL, "/") != serverURL:
+		return fmt.Errorf("the Rancher CLI server %s does not match the Rancher server %s: select the matching server with --rancher-cli-server", serverURL, c.RancherServerURL)
+	}
+	if c.CAFile == "" && c.CAData == "" {
+		c.CAData = server.CACert
+	}
+
+	if c.RancherToken != "" || (c.RancherAccessKey != "" && c.RancherSecretKey != "") {
+		return nil
+	}
+	switch {
+	case server.TokenKey != "":
+		c.RancherToken = server.TokenKey
+	case server.AccessKey != "" && server.SecretKey != "":
+		c.RancherAccessKey = server.AccessKey
+		c.RancherSecretKey = server.SecretKey
+	}
+	return nil
+}
+
+// readSecret reads a secret from a file, or from stdin when path is "-", trimming surrounding whitespace.
+func readSecret(path string) (string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(expandHome(path))
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileConfig is the on-disk configuration file holding named profiles.
+//
+// Example (~/.config/rancher-projects/config.yaml):
+//
+//	defaultProfile: prod
+//	profiles:
+//	  prod:
+//	    server: https://rancher.example.com
+//	    credentials:
+//	      accessKey: token-abcde
+//	      secretKeyEnv: PROD_RANCHER_SECRET_KEY
+//	      # or token / tokenEnv / tokenFile with a token-xxxxx:secret bearer token
+//	    tls:
+//	      caFile: /etc/ssl/private-ca.pem
+//	    defaults:
+//	      clusterName: prod-rke2
+//	      kubeconfigDir: ~/.kube/prod
+//	    webhooks:
+//	      - url: https://hooks.slack.com/services/T000/B000/XXXX
+//	        format: slack
+//	      - url: https://automation.example.com/rancher-projects
+//	        secretEnv: WEBHOOK_SECRET
+//	projectTemplates:
+//	  team:
+//	    podSecurity: restricted
+//	    resourceQuota:
+//	      limit:
+//	        pods: "200"
+//	    members:
+//	      - role: project-owner
+//	        group: github_team://1234
+//	    namespaces: [ci]
+type FileConfig struct {
+	DefaultProfile   string                     `yaml:"defaultProfile"`
+	Profiles         map[string]Profile         `yaml:"profiles"`
+	ProjectTemplates map[string]ProjectTemplate `yaml:"projectTemplates"`
+}
+
+// Profile describes a single Rancher server and the defaults used with it.
+type Profile struct {
+	Server      string             `yaml:"server"`
+	Credentials ProfileCredentials `yaml:"credentials"`
+	TLS         ProfileTLS         `yaml:"tls"`
+	Defaults    ProfileDefaults    `yaml:"defaults"`
+	Webhooks    []ProfileWebhook   `yaml:"webhooks"`
+}
+
+// ProfileCredentials holds the Rancher API key, either inline or as a reference
+// to an environment variable or file so secrets can stay out of the config file.
+type ProfileCredentials struct {
+	AccessKey     string `yaml:"accessKey"`
+	AccessKeyEnv  string `yaml:"accessKeyEnv"`
+	SecretKey     string `yaml:"secretKey"`
+	SecretKeyEnv  string `yaml:"secretKeyEnv"`
+	SecretKeyFile string `yaml:"secretKeyFile"`
+	Token         string `yaml:"token"`
+	TokenEnv      string `yaml:"tokenEnv"`
+	TokenFile     string `yaml:"tokenFile"`
+}
+
+// ProfileTLS holds the TLS settings used when talking to the Rancher server.
+type ProfileTLS struct {
+	CAFile             string `yaml:"caFile"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+	ClientCert         string `yaml:"clientCert"`
+	ClientKey          string `yaml:"clientKey"`
+	BootstrapCACerts   bool   `yaml:"bootstrapCACerts"`
+	CAChecksum         string `yaml:"caChecksum"`
+}
+
+// ProfileDefaults holds default values for regular command line options.
+type ProfileDefaults struct {
+	ClusterName      string `yaml:"clusterName"`
+	ClusterType      string `yaml:"clusterType"`
+	ClusterLabels    string `yaml:"clusterLabels"`
+	ProjectName      string `yaml:"projectName"`
+	Namespace        string `yaml:"namespace"`
+	KubeconfigDir    string `yaml:"kubeconfigDir"`
+	KubeconfigPrefix string `yaml:"kubeconfigPrefix"`
+}
+
+// DefaultConfigFile returns the default location of the configuration file,
+// honouring XDG_CONFIG_HOME when it is set.
+func DefaultConfigFile() string {
+	return defaultConfigFile(os.LookupEnv)
+}
+
+func defaultConfigFile(lookupEnv func(string) (string, bool)) string {
+	if dir, _ := lookupEnv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "rancher-projects", "config.yaml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "rancher-projects", "config.yaml")
+}
+
+// ReadConfigFile reads and parses the configuration file at path.
+func ReadConfigFile(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var fc FileConfig
+	if err := yaml.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return &fc, nil
+}
+
+// Profile returns the named profile, falling back to the file's default profile
+// when name is empty. It returns nil without error if no profile is selected.
+func (fc *FileConfig) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = fc.DefaultProfile
+	}
+	if name == "" {
+		return nil, nil
+	}
+	p, ok := fc.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in config file", name)
+	}
+	return &p, nil
+}
+
+// loadProfile resolves the configuration file and profile to use. A missing
+// config file is only an error when it was requested explicitly.
+func loadProfile(path, name string, explicitPath bool) (*FileConfig, *Profile, error) {
+	if path == "" {
+		return nil, nil, nil
+	}
+	fc, err := ReadConfigFile(path)
+	if err != nil {
+		if !explicitPath && errors.Is(err, os.ErrNotExist) {
+			if name != "" {
+				return nil, nil, fmt.Errorf("profile %q requested but config file %s does not exist", name, path)
+			}
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	profile, err := fc.Profile(name)
+	return fc, profile, err
+}
+
+// apply copies the profile's settings into c, resolving credential references with lookupEnv.
+func (p *Profile) apply(c *Config, lookupEnv func(string) (string, bool)) error {
+	c.RancherServerURL = p.Server
+
+	accessKey := p.Credentials.AccessKey
+	if p.Credentials.AccessKeyEnv != "" {
+		accessKey, _ = lookupEnv(p.Credentials.AccessKeyEnv)
+	}
+	c.RancherAccessKey = accessKey
+
+	secretKey := p.Credentials.SecretKey
+	switch {
+	case p.Credentials.SecretKeyEnv != "":
+		secretKey, _ = lookupEnv(p.Credentials.SecretKeyEnv)
+	case p.Credentials.SecretKeyFile != "":
+		secret, err := readSecret(p.Credentials.SecretKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read secret key file: %w", err)
+		}
+		secretKey = secret
+	}
+	c.RancherSecretKey = secretKey
+
+	token := p.Credentials.Token
+	switch {
+	case p.Credentials.TokenEnv != "":
+		token, _ = lookupEnv(p.Credentials.TokenEnv)
+	case p.Credentials.TokenFile != "":
+		secret, err := readSecret(p.Credentials.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read token file: %w", err)
+		}
+		token = secret
+	}
+	c.RancherToken = token
+
+	c.CAFile = expandHome(p.TLS.CAFile)
+	c.InsecureSkipTLSVerify = p.TLS.InsecureSkipVerify
+	c.ClientCertFile = expandHome(p.TLS.ClientCert)
+	c.ClientKeyFile = expandHome(p.TLS.ClientKey)
+	c.BootstrapCACerts = p.TLS.BootstrapCACerts
+	c.CAChecksum = p.TLS.CAChecksum
+
+	c.ClusterName = p.Defaults.ClusterName
+	c.ClusterType = p.Defaults.ClusterType
+	c.ClusterLabels = p.Defaults.ClusterLabels
+	c.ProjectName = p.Defaults.ProjectName
+	c.Namespace = p.Defaults.Namespace
+	c.KubeconfigDir = expandHome(p.Defaults.KubeconfigDir)
+	c.KubeconfigPrefix = p.Defaults.KubeconfigPrefix
+
+	for _, w := range p.Webhooks {
+		secret := w.Secret
+		if w.SecretEnv != "" {
+			secret, _ = lookupEnv(w.SecretEnv)
+		}
+		c.Webhooks = append(c.Webhooks, Webhook{URL: w.URL, Format: w.Format, Secret: secret})
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
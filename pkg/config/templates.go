@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/supporttools/rancher-projects/pkg/podsecurity"
+)
+
+// ProjectTemplate is a named project setup from the configuration file, applied with
+// --project-template. Quotas and limits use the Rancher API field layout.
+type ProjectTemplate struct {
+	Description                   string                 `yaml:"description"`
+	Labels                        map[string]string      `yaml:"labels"`
+	ResourceQuota                 map[string]interface{} `yaml:"resourceQuota"`
+	NamespaceDefaultResourceQuota map[string]interface{} `yaml:"namespaceDefaultResourceQuota"`
+	ContainerDefaultResourceLimit map[string]interface{} `yaml:"containerDefaultResourceLimit"`
+	PodSecurity                   string                 `yaml:"podSecurity"`
+	Members                       []TemplateMember       `yaml:"members"`
+	Namespaces                    []string               `yaml:"namespaces"`
+}
+
+// TemplateMember grants a role template to a user or group principal, e.g. local://u-abc12
+// or github_team://1234.
+type TemplateMember struct {
+	Role  string `yaml:"role"`
+	User  string `yaml:"user"`
+	Group string `yaml:"group"`
+}
+
+// validateProjectTemplates reports an unknown --project-template and invalid templates.
+func (c *Config) validateProjectTemplates() error {
+	names := make([]string, 0, len(c.ProjectTemplates))
+	for name := range c.ProjectTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []error
+	if c.ProjectTemplate != "" {
+		if _, ok := c.ProjectTemplates[c.ProjectTemplate]; !ok {
+			problems = append(problems, fmt.Errorf("unknown project template %q: the configuration file defines %q", c.ProjectTemplate, names))
+		}
+		if c.ProjectName == "" {
+			problems = append(problems, errors.New("--project-template requires --project-name"))
+		}
+	}
+
+	for _, name := range names {
+		t := c.ProjectTemplates[name]
+		if t.PodSecurity != "" && !podsecurity.Valid(t.PodSecurity) {
+			problems = append(problems, fmt.Errorf("project template %s: invalid Pod Security level %q: expected %s", name, t.PodSecurity, strings.Join(podsecurity.Levels, ", ")))
+		}
+		for i, m := range t.Members {
+			if m.Role == "" || (m.User == "") == (m.Group == "") {
+				problems = append(problems, fmt.Errorf("project template %s: member %d needs a role and either a user or a group", name, i+1))
+			}
+		}
+	}
+	return errors.Join(problems...)
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Webhook payload formats.
+const (
+	WebhookFormatJSON  = "json"
+	WebhookFormatSlack = "slack"
+	WebhookFormatTeams = "teams"
+)
+
+// Webhook is an endpoint notified about changes made by the tool. Bodies are signed with
+// HMAC-SHA256 when Secret is set.
+type Webhook struct {
+	URL    string
+	Format string
+	Secret string
+}
+
+// ProfileWebhook configures a webhook in a profile. The secret can be given inline or by
+// naming an environment variable.
+type ProfileWebhook struct {
+	URL       string `yaml:"url"`
+	Format    string `yaml:"format"`
+	Secret    string `yaml:"secret"`
+	SecretEnv string `yaml:"secretEnv"`
+}
+
+// stringList is a flag that can be repeated and also accepts comma separated values. The
+// first use replaces the default, later uses append to it.
+type stringList struct {
+	values *[]string
+	set    bool
+}
+
+func (l *stringList) String() string {
+	if l.values == nil {
+		return ""
+	}
+	return strings.Join(*l.values, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	if !l.set {
+		*l.values = nil
+		l.set = true
+	}
+	*l.values = append(*l.values, splitList(value)...)
+	return nil
+}
+
+// splitList splits a comma separated list, dropping empty entries.
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// resolveWebhooks adds the webhooks given by --webhook to those from the profile.
+func (c *Config) resolveWebhooks() {
+	for _, u := range c.WebhookURLs {
+		c.Webhooks = append(c.Webhooks, Webhook{URL: u, Format: c.WebhookFormat, Secret: c.WebhookSecret})
+	}
+}
+
+// validateWebhooks reports webhooks with an invalid URL or format.
+func (c *Config) validateWebhooks() error {
+	var problems []error
+	for _, w := range c.Webhooks {
+		if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			problems = append(problems, fmt.Errorf("invalid webhook URL %q", w.URL))
+		}
+		switch w.Format {
+		case "", WebhookFormatJSON, WebhookFormatSlack, WebhookFormatTeams:
+		default:
+			problems = append(problems, fmt.Errorf("invalid webhook format %q: expected json, slack or teams", w.Format))
+		}
+	}
+	return errors.Join(problems...)
+}
@@ -0,0 +1,37 @@
+// Package daemon runs a reconcile cycle repeatedly until it is stopped.
+package daemon
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// randDuration returns a random duration in [0, max). It is a variable so tests can make it deterministic.
+var randDuration = func(max time.Duration) time.Duration {
+	return time.Duration(rand.Int63n(int64(max))) //nolint:gosec // jitter does not need a secure source
+}
+
+// Run calls cycle immediately and then again interval plus a random delay of up to jitter after
+// each cycle finishes, until ctx is cancelled. Cycles never overlap.
+func Run(ctx context.Context, interval, jitter time.Duration, cycle func(ctx context.Context)) {
+	for {
+		cycle(ctx)
+
+		timer := time.NewTimer(NextDelay(interval, jitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// NextDelay returns interval plus a random delay of up to jitter.
+func NextDelay(interval, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	return interval + randDuration(jitter)
+}
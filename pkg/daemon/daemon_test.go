@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cycles := 0
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, time.Millisecond, time.Millisecond, func(context.Context) {
+			cycles++
+			if cycles == 3 {
+				cancel()
+			}
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+	assert.Equal(t, 3, cycles)
+}
+
+func TestNextDelay(t *testing.T) {
+	original := randDuration
+	defer func() { randDuration = original }()
+	randDuration = func(max time.Duration) time.Duration { return max / 2 }
+
+	assert.Equal(t, time.Minute, NextDelay(time.Minute, 0))
+	assert.Equal(t, time.Minute+15*time.Second, NextDelay(time.Minute, 30*time.Second))
+}
@@ -0,0 +1,336 @@
+// Package guide guides an operator through picking a cluster, a project and a namespace,
+// then assigns the namespace and generates a kubeconfig like the default command would.
+//
+// Example session:
+//
+//	Clusters:
+//	  1) downstream  rke2  v1.27.6+rke2r1
+//	  2) edge-01     k3s   v1.28.2+k3s1
+//	Cluster [number, or text to filter]: edge
+//	...
+//
+// At the end the equivalent non-interactive command line is printed for reuse.
+package guide
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/rancher"
+)
+
+// ErrAborted is returned when the operator declines the final confirmation.
+var ErrAborted = errors.New("aborted")
+
+// createChoice is typed instead of a number to create a new project or namespace.
+const createChoice = "+"
+
+// Session is one guided run: it writes prompts to an output and reads the answers from an input.
+type Session struct {
+	cfg     *config.Config
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+// New returns a session for cfg. Options already set in cfg, such as --cluster-name,
+// --project-name or --namespace, are not asked for.
+func New(cfg *config.Config, in io.Reader, out io.Writer) *Session {
+	return &Session{cfg: cfg, scanner: bufio.NewScanner(in), out: out}
+}
+
+// Run asks for the cluster, project and namespace, performs the assignment and kubeconfig
+// generation through rancher.SingleCluster and prints the equivalent command line.
+func (s *Session) Run() error {
+	cluster, err := s.chooseCluster()
+	if err != nil {
+		return err
+	}
+	s.cfg.ClusterName = cluster.Name
+
+	if err := s.chooseProject(cluster); err != nil {
+		return err
+	}
+	if err := s.chooseNamespace(cluster); err != nil {
+		return err
+	}
+	if !s.cfg.CreateKubeconfig {
+		if s.cfg.CreateKubeconfig, err = s.confirm("Generate a kubeconfig?", true); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(s.out)
+	fmt.Fprintf(s.out, "Cluster:   %s (%s)\n", cluster.Name, cluster.ID)
+	fmt.Fprintf(s.out, "Project:   %s%s\n", s.cfg.ProjectName, newSuffix(s.cfg.CreateProject))
+	fmt.Fprintf(s.out, "Namespace: %s%s\n", s.cfg.Namespace, newSuffix(s.cfg.CreateNamespace))
+	if s.cfg.CreateKubeconfig {
+		fmt.Fprintf(s.out, "Kubeconfig: %s\n", rancher.KubeconfigPath(s.cfg, cluster.Name))
+	}
+	ok, err := s.confirm("Proceed?", false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAborted
+	}
+
+	if err := rancher.SingleCluster(s.cfg); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(s.out)
+	fmt.Fprintln(s.out, "Done. To do the same without prompts, run:")
+	fmt.Fprintf(s.out, "  %s\n", CommandLine(s.cfg))
+	return nil
+}
+
+// chooseCluster lists the active clusters matching the cluster selection options and lets the
+// operator pick one by number or narrow the list down by typing part of a name.
+func (s *Session) chooseCluster() (*rancher.Cluster, error) {
+	if s.cfg.ClusterName != "" {
+		return rancher.GetCluster(s.cfg, s.cfg.ClusterName)
+	}
+
+	// The inventory honours --cluster-type, --cluster-labels and --kubernetes-version.
+	inventory, err := rancher.ClusterInventory(s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	var active []rancher.Cluster
+	for _, c := range inventory.Clusters {
+		if c.Active() {
+			active = append(active, c)
+		}
+	}
+	if len(active) == 0 {
+		return nil, errors.New("no active clusters found")
+	}
+
+	filter := ""
+	for {
+		var shown []rancher.Cluster
+		for _, c := range active {
+			if strings.Contains(strings.ToLower(c.Name), strings.ToLower(filter)) {
+				shown = append(shown, c)
+			}
+		}
+		if len(shown) == 0 {
+			fmt.Fprintf(s.out, "No cluster matches %q.\n", filter)
+			shown, filter = active, ""
+		}
+
+		fmt.Fprintln(s.out, "Clusters:")
+		for i, c := range shown {
+			fmt.Fprintf(s.out, "  %d) %s  %s  %s\n", i+1, c.Name, c.Provider, c.KubernetesVersion)
+		}
+		answer, err := s.ask("Cluster [number, or text to filter]")
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := pick(answer, len(shown)); ok {
+			return &shown[i], nil
+		}
+		filter = answer
+	}
+}
+
+// chooseProject lets the operator pick a project of the cluster or name a new one.
+func (s *Session) chooseProject(cluster *rancher.Cluster) error {
+	if s.cfg.ProjectName != "" {
+		return nil
+	}
+
+	projects, err := rancher.ListProjects(s.cfg, cluster.ID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+
+	fmt.Fprintln(s.out, "Projects:")
+	for i, p := range projects {
+		fmt.Fprintf(s.out, "  %d) %s\n", i+1, p.Name)
+	}
+	fmt.Fprintf(s.out, "  %s) create a new project\n", createChoice)
+	for {
+		answer, err := s.ask("Project")
+		if err != nil {
+			return err
+		}
+		if i, ok := pick(answer, len(projects)); ok {
+			s.cfg.ProjectName = projects[i].Name
+			return nil
+		}
+		if answer == createChoice {
+			if s.cfg.ProjectName, err = s.askName("New project name"); err != nil {
+				return err
+			}
+			s.cfg.CreateProject = true
+			return nil
+		}
+		fmt.Fprintf(s.out, "Enter a number between 1 and %d or %s.\n", len(projects), createChoice)
+	}
+}
+
+// chooseNamespace lets the operator pick a namespace of the cluster, shown with the project it
+// is in, or name a new one.
+func (s *Session) chooseNamespace(cluster *rancher.Cluster) error {
+	if s.cfg.Namespace != "" {
+		return nil
+	}
+
+	namespaces, err := rancher.ListNamespaces(s.cfg, cluster.ID)
+	if err != nil {
+		return err
+	}
+	projects, err := rancher.ListProjects(s.cfg, cluster.ID)
+	if err != nil {
+		return err
+	}
+	projectNames := make(map[string]string, len(projects))
+	for _, p := range projects {
+		projectNames[p.Id] = p.Name
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+
+	fmt.Fprintln(s.out, "Namespaces:")
+	for i, ns := range namespaces {
+		project := "-"
+		if id := ns.ProjectID(); id != "" {
+			if project = projectNames[id]; project == "" {
+				project = id
+			}
+		}
+		fmt.Fprintf(s.out, "  %d) %s  %s\n", i+1, ns.Name, project)
+	}
+	fmt.Fprintf(s.out, "  %s) create a new namespace\n", createChoice)
+	for {
+		answer, err := s.ask("Namespace")
+		if err != nil {
+			return err
+		}
+		if i, ok := pick(answer, len(namespaces)); ok {
+			s.cfg.Namespace = namespaces[i].Name
+			return nil
+		}
+		if answer == createChoice {
+			if s.cfg.Namespace, err = s.askName("New namespace name"); err != nil {
+				return err
+			}
+			s.cfg.CreateNamespace = true
+			return nil
+		}
+		fmt.Fprintf(s.out, "Enter a number between 1 and %d or %s.\n", len(namespaces), createChoice)
+	}
+}
+
+// ask prints a prompt and returns the trimmed answer. It fails when the input ends.
+func (s *Session) ask(prompt string) (string, error) {
+	fmt.Fprintf(s.out, "%s: ", prompt)
+	if !s.scanner.Scan() {
+		if err := s.scanner.Err(); err != nil {
+			return "", fmt.Errorf("failed to read answer: %v", err)
+		}
+		return "", fmt.Errorf("no answer to %q: input closed", prompt)
+	}
+	return strings.TrimSpace(s.scanner.Text()), nil
+}
+
+// askName asks until the answer is not empty.
+func (s *Session) askName(prompt string) (string, error) {
+	for {
+		answer, err := s.ask(prompt)
+		if err != nil || answer != "" {
+			return answer, err
+		}
+	}
+}
+
+// confirm asks a yes/no question; an empty answer means def.
+func (s *Session) confirm(prompt string, def bool) (bool, error) {
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+	for {
+		answer, err := s.ask(prompt + " " + hint)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// pick converts a 1-based answer to an index in a list of n items.
+func pick(answer string, n int) (int, bool) {
+	i, err := strconv.Atoi(answer)
+	if err != nil || i < 1 || i > n {
+		return 0, false
+	}
+	return i - 1, true
+}
+
+func newSuffix(created bool) string {
+	if created {
+		return " (new)"
+	}
+	return ""
+}
+
+// CommandLine returns the rancher-projects command line that repeats the choices in cfg without
+// prompts. Credentials are left out; the profile is kept so the command runs against the same server.
+func CommandLine(cfg *config.Config) string {
+	args := []string{"rancher-projects"}
+	if cfg.Profile != "" {
+		args = append(args, "--profile", quote(cfg.Profile))
+	}
+	args = append(args, "--cluster-name", quote(cfg.ClusterName), "--project-name", quote(cfg.ProjectName))
+	if cfg.CreateProject {
+		args = append(args, "--create-project")
+	}
+	if cfg.ProjectTemplate != "" {
+		args = append(args, "--project-template", quote(cfg.ProjectTemplate))
+	}
+	if cfg.PodSecurity != "" {
+		args = append(args, "--pod-security", quote(cfg.PodSecurity))
+	}
+	if cfg.NetworkIsolation {
+		args = append(args, "--network-isolation")
+	}
+	if cfg.Namespace != "" {
+		args = append(args, "--namespace", quote(cfg.Namespace))
+	}
+	if cfg.CreateNamespace {
+		args = append(args, "--create-namespace")
+		if cfg.CreateNetworkPolicies {
+			args = append(args, "--create-network-policies")
+		}
+	}
+	if cfg.CreateKubeconfig {
+		args = append(args, "--create-kubeconfig")
+		if cfg.KubeconfigFile != "" {
+			args = append(args, "--kubeconfig", quote(cfg.KubeconfigFile))
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// quote single-quotes a value for a POSIX shell when it contains anything but safe characters.
+func quote(value string) string {
+	if value != "" && strings.Trim(value, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.:/=") == "" {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,87 @@
+package guide
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/rancher"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestMain(m *testing.M) {
+	rancher.NamespaceSettleDelay = 0
+	os.Exit(m.Run())
+}
+
+func TestRunCreatesProjectAndNamespace(t *testing.T) {
+	srv := ranchertest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddCluster(ranchertest.Cluster{Name: "downstream", Provider: "rke2"})
+	srv.AddCluster(ranchertest.Cluster{Name: "offline", State: "unavailable"})
+	edge := srv.AddCluster(ranchertest.Cluster{Name: "edge-01", Provider: "k3s"})
+	srv.AddProject(edge.ID, "Default")
+
+	cfg := srv.Config()
+	cfg.Profile = "prod"
+	// Filter the clusters, pick edge-01, create a project and a namespace, skip the kubeconfig and confirm.
+	in := strings.NewReader("edge\n1\n+\nTeamA\n+\napps\nn\ny\n")
+	var out strings.Builder
+	require.NoError(t, New(cfg, in, &out).Run())
+
+	project, ok := srv.Project(edge.ID, "TeamA")
+	require.True(t, ok, "project should have been created")
+	ns, ok := srv.Namespace(edge.ID, "apps")
+	require.True(t, ok, "namespace should have been created")
+	assert.Equal(t, project.ID, ns.ProjectID())
+
+	assert.NotContains(t, out.String(), "offline", "inactive clusters are not offered")
+	assert.Contains(t, out.String(), "1) Default")
+	assert.Contains(t, out.String(),
+		"rancher-projects --profile prod --cluster-name edge-01 --project-name TeamA --create-project --namespace apps --create-namespace\n")
+}
+
+func TestRunAborted(t *testing.T) {
+	srv := ranchertest.NewServer()
+	t.Cleanup(srv.Close)
+	cluster := srv.AddCluster(ranchertest.Cluster{Name: "downstream"})
+	srv.AddProject(cluster.ID, "Default")
+	srv.AddNamespace(cluster.ID, "apps", "")
+
+	cfg := srv.Config()
+	cfg.ClusterName = cluster.Name
+	// An invalid choice is asked again.
+	in := strings.NewReader("7\n1\n1\n\nn\n")
+	var out strings.Builder
+	err := New(cfg, in, &out).Run()
+	assert.ErrorIs(t, err, ErrAborted)
+	assert.Contains(t, out.String(), "Enter a number between 1 and 1 or +.")
+
+	ns, ok := srv.Namespace(cluster.ID, "apps")
+	require.True(t, ok)
+	assert.Empty(t, ns.ProjectID(), "nothing is changed when the operator does not confirm")
+}
+
+func TestRunInputClosed(t *testing.T) {
+	srv := ranchertest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddCluster(ranchertest.Cluster{Name: "downstream"})
+
+	err := New(srv.Config(), strings.NewReader(""), &strings.Builder{}).Run()
+	assert.ErrorContains(t, err, "input closed")
+}
+
+func TestCommandLine(t *testing.T) {
+	cfg := &config.Config{
+		ClusterName:      "downstream",
+		ProjectName:      "Bob's",
+		Namespace:        "apps",
+		CreateKubeconfig: true,
+		KubeconfigFile:   "/tmp/kube config",
+	}
+	assert.Equal(t, `rancher-projects --cluster-name downstream --project-name 'Bob'\''s' --namespace apps --create-kubeconfig --kubeconfig '/tmp/kube config'`, CommandLine(cfg))
+}
@@ -0,0 +1,154 @@
+// Package kubeversion parses Kubernetes versions and the version ranges used to select clusters,
+// e.g. for an upgrade campaign:
+//
+//	<1.28          every cluster older than 1.28
+//	>=1.26,<1.28   1.26.x and 1.27.x
+//	=1.27.6        exactly 1.27.6
+//
+// A range is a comma-separated list of constraints that must all hold. A constraint without a
+// patch version compares the minor version only, so "<=1.27" includes 1.27.6 and ">1.27"
+// excludes it.
+package kubeversion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a Kubernetes version.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// Parse parses a version such as "v1.27.6+rke2r1", "1.28.2-k3s1" or "1.28". The leading "v"
+// and any pre-release or build suffix are ignored.
+func Parse(s string) (Version, error) {
+	v, _, err := parse(s)
+	return v, err
+}
+
+func parse(s string) (Version, bool, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(trimmed, "+-"); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+
+	parts := strings.Split(trimmed, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, false, fmt.Errorf("invalid version %q: expected major.minor[.patch]", s)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, false, fmt.Errorf("invalid version %q: expected major.minor[.patch]", s)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, len(parts) == 3, nil
+}
+
+// String returns the version as major.minor.patch.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or 1 when v is older than, equal to or newer than o.
+func (v Version) Compare(o Version) int {
+	return compare(v, o, true)
+}
+
+func compare(v, o Version, patch bool) int {
+	switch {
+	case v.Major != o.Major:
+		return sign(v.Major - o.Major)
+	case v.Minor != o.Minor:
+		return sign(v.Minor - o.Minor)
+	case patch:
+		return sign(v.Patch - o.Patch)
+	}
+	return 0
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// Operators lists the supported constraint operators. Two-character operators come first so
+// they are matched before their one-character prefixes.
+var Operators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+type constraint struct {
+	op      string
+	version Version
+	patch   bool
+}
+
+func (c constraint) holds(v Version) bool {
+	cmp := compare(v, c.version, c.patch)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case "!=":
+		return cmp != 0
+	}
+	return cmp == 0
+}
+
+// Range is a set of version constraints that must all hold.
+type Range struct {
+	constraints []constraint
+}
+
+// ParseRange parses a comma-separated list of constraints such as ">=1.26,<1.28". A constraint
+// without an operator means "=".
+func ParseRange(s string) (Range, error) {
+	var r Range
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return Range{}, fmt.Errorf("invalid version range %q: empty constraint", s)
+		}
+
+		op := "="
+		for _, candidate := range Operators {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = part[len(candidate):]
+				break
+			}
+		}
+
+		version, patch, err := parse(part)
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid version range %q: %v", s, err)
+		}
+		r.constraints = append(r.constraints, constraint{op: op, version: version, patch: patch})
+	}
+	return r, nil
+}
+
+// Contains reports whether v satisfies every constraint of the range.
+func (r Range) Contains(v Version) bool {
+	for _, c := range r.constraints {
+		if !c.holds(v) {
+			return false
+		}
+	}
+	return true
+}
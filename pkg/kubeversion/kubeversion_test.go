@@ -0,0 +1,63 @@
+package kubeversion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for input, want := range map[string]Version{
+		"v1.27.6+rke2r1": {1, 27, 6},
+		"v1.28.2+k3s1":   {1, 28, 2},
+		"1.26.15-eks-1":  {1, 26, 15},
+		"1.28":           {1, 28, 0},
+	} {
+		got, err := Parse(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"", "1", "v1.x.3", "1.2.3.4", "latest"} {
+		_, err := Parse(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	tests := []struct {
+		rng      string
+		version  string
+		contains bool
+	}{
+		{"<1.28", "v1.27.6+rke2r1", true},
+		{"<1.28", "v1.28.0+rke2r1", false},
+		{"<=1.27", "v1.27.9", true},
+		{">1.27", "v1.27.9", false},
+		{">1.27", "v1.28.1", true},
+		{">=1.26,<1.28", "v1.26.0", true},
+		{">=1.26,<1.28", "v1.25.16", false},
+		{">=1.26,<1.28", "v1.28.2", false},
+		{"1.27", "v1.27.3", true},
+		{"=1.27.6", "v1.27.6+k3s1", true},
+		{"==1.27.6", "v1.27.7", false},
+		{"!=1.27", "v1.27.7", false},
+		{"!=1.27", "v1.28.0", true},
+		{"< 1.28, >= 1.27.4", "v1.27.3", false},
+	}
+	for _, tt := range tests {
+		r, err := ParseRange(tt.rng)
+		require.NoError(t, err, tt.rng)
+		v, err := Parse(tt.version)
+		require.NoError(t, err, tt.version)
+		assert.Equal(t, tt.contains, r.Contains(v), "%s contains %s", tt.rng, tt.version)
+	}
+}
+
+func TestParseRangeErrors(t *testing.T) {
+	for _, input := range []string{"", "<", ">=1.26,", "~1.27", "<1.x"} {
+		_, err := ParseRange(input)
+		assert.Error(t, err, input)
+	}
+}
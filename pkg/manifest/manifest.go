@@ -0,0 +1,115 @@
+// Package manifest describes the projects and namespaces that should exist in each
+// selected cluster.
+//
+// Example:
+//
+//	projects:
+//	  - name: ClusterServices
+//	    description: Shared platform services
+//	    namespaces:
+//	      - monitoring
+//	      - logging
+//	  - name: TeamA
+//	    podSecurity: restricted
+//	    namespaces:
+//	      - team-a
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/supporttools/rancher-projects/pkg/podsecurity"
+)
+
+// Manifest is the desired state applied to, or compared with, each selected cluster.
+type Manifest struct {
+	Projects []Project `yaml:"projects" json:"projects"`
+}
+
+// Project is a desired project and the namespaces assigned to it.
+type Project struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	PodSecurity string   `yaml:"podSecurity,omitempty" json:"podSecurity,omitempty"`
+	Namespaces  []string `yaml:"namespaces,omitempty" json:"namespaces,omitempty"`
+}
+
+// Load reads and validates the manifest at path.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Write encodes the manifest as YAML, or as JSON when format is "json".
+func (m *Manifest) Write(w io.Writer, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(m)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(m); err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return encoder.Close()
+}
+
+// Validate reports projects without a name, duplicate projects, invalid Pod Security levels and
+// namespaces listed more than once.
+func (m *Manifest) Validate() error {
+	var problems []error
+
+	projects := map[string]bool{}
+	namespaces := map[string]string{}
+	for i, p := range m.Projects {
+		if p.Name == "" {
+			problems = append(problems, fmt.Errorf("project %d has no name", i+1))
+			continue
+		}
+		if projects[p.Name] {
+			problems = append(problems, fmt.Errorf("project %s is listed more than once", p.Name))
+		}
+		projects[p.Name] = true
+		if p.PodSecurity != "" && !podsecurity.Valid(p.PodSecurity) {
+			problems = append(problems, fmt.Errorf("project %s has invalid Pod Security level %q", p.Name, p.PodSecurity))
+		}
+
+		for _, ns := range p.Namespaces {
+			if owner, ok := namespaces[ns]; ok {
+				problems = append(problems, fmt.Errorf("namespace %s is listed in projects %s and %s", ns, owner, p.Name))
+				continue
+			}
+			namespaces[ns] = p.Name
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// Project returns the desired project with the given name.
+func (m *Manifest) Project(name string) (Project, bool) {
+	for _, p := range m.Projects {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Project{}, false
+}
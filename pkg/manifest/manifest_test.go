@@ -0,0 +1,69 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+projects:
+  - name: ClusterServices
+    description: Shared services
+    namespaces: [monitoring, logging]
+  - name: TeamA
+`), 0o600))
+
+	m, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Project{
+		{Name: "ClusterServices", Description: "Shared services", Namespaces: []string{"monitoring", "logging"}},
+		{Name: "TeamA"},
+	}, m.Projects)
+
+	p, ok := m.Project("TeamA")
+	assert.True(t, ok)
+	assert.Equal(t, "TeamA", p.Name)
+}
+
+func TestValidate(t *testing.T) {
+	m := &Manifest{Projects: []Project{
+		{Name: "A", Namespaces: []string{"shared"}},
+		{Name: "A"},
+		{Name: "B", Namespaces: []string{"shared"}},
+		{},
+		{Name: "C", PodSecurity: "strict"},
+	}}
+
+	err := m.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "project A is listed more than once")
+	assert.Contains(t, err.Error(), "namespace shared is listed in projects A and B")
+	assert.Contains(t, err.Error(), "project 4 has no name")
+	assert.Contains(t, err.Error(), `project C has invalid Pod Security level "strict"`)
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	m := &Manifest{Projects: []Project{
+		{Name: "ClusterServices", Description: "Shared services", Namespaces: []string{"logging", "monitoring"}},
+		{Name: "Empty"},
+	}}
+
+	for _, format := range []string{"yaml", "json"} {
+		path := filepath.Join(t.TempDir(), "projects."+format)
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, m.Write(f, format))
+		require.NoError(t, f.Close())
+
+		// YAML is a superset of JSON, so Load reads both.
+		loaded, err := Load(path)
+		require.NoError(t, err, format)
+		assert.Equal(t, m.Projects, loaded.Projects, format)
+	}
+}
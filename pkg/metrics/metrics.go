@@ -0,0 +1,191 @@
+// Package metrics defines the Prometheus metrics of rancher-projects and serves them over HTTP.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Default is the registry holding the metrics of rancher-projects.
+var Default = prometheus.NewRegistry()
+
+// DefaultBuckets are latency buckets in seconds suited to Rancher API calls.
+var DefaultBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+var factory = promauto.With(Default)
+
+var (
+	// APIRequests counts requests to the Rancher API by method, endpoint and status code.
+	// Requests that failed without a response have status "error".
+	APIRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "rancher_projects_api_requests_total",
+		Help: "Requests sent to the Rancher API.",
+	}, []string{"method", "endpoint", "status"})
+	// APIRequestDuration observes the latency of Rancher API requests.
+	APIRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rancher_projects_api_request_duration_seconds",
+		Help:    "Latency of requests sent to the Rancher API.",
+		Buckets: DefaultBuckets,
+	}, []string{"method", "endpoint"})
+
+	// ReconcileCycles counts reconcile cycles by result, success or error.
+	ReconcileCycles = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "rancher_projects_reconcile_cycles_total",
+		Help: "Reconcile cycles run.",
+	}, []string{"result"})
+	// ReconcileDuration observes the duration of reconcile cycles.
+	ReconcileDuration = factory.NewHistogram(prometheus.HistogramOpts{
+		Name:    "rancher_projects_reconcile_duration_seconds",
+		Help:    "Duration of reconcile cycles.",
+		Buckets: DefaultBuckets,
+	})
+	// LastReconcile is the time the last reconcile cycle finished, by result.
+	LastReconcile = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rancher_projects_last_reconcile_timestamp_seconds",
+		Help: "Unix time the last reconcile cycle finished.",
+	}, []string{"result"})
+	// ReconciledClusters is the number of clusters selected by the last reconcile cycle.
+	ReconciledClusters = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "rancher_projects_reconciled_clusters",
+		Help: "Clusters selected by the last reconcile cycle.",
+	})
+
+	// ProjectsCreated counts projects created by the tool.
+	ProjectsCreated = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "rancher_projects_projects_created_total",
+		Help: "Projects created.",
+	}, []string{"cluster_id"})
+	// NamespacesCreated counts namespaces created by the tool.
+	NamespacesCreated = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "rancher_projects_namespaces_created_total",
+		Help: "Namespaces created.",
+	}, []string{"cluster_id"})
+	// NamespacesAssigned counts namespaces assigned to a project by the tool.
+	NamespacesAssigned = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "rancher_projects_namespaces_assigned_total",
+		Help: "Namespaces assigned to a project.",
+	}, []string{"cluster_id"})
+	// DriftFindings is the number of differences found per cluster and kind by the last reconcile
+	// cycle or drift check, before anything was changed.
+	DriftFindings = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rancher_projects_drift_findings",
+		Help: "Differences between the desired and the actual state found by the last check.",
+	}, []string{"cluster_id", "kind"})
+
+	// HTTPRequests counts requests served by the self-service API by route and status code.
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "rancher_projects_http_requests_total",
+		Help: "Requests served by the self-service API.",
+	}, []string{"method", "route", "status"})
+	// HTTPRequestDuration observes the latency of requests served by the self-service API.
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rancher_projects_http_request_duration_seconds",
+		Help:    "Latency of requests served by the self-service API.",
+		Buckets: DefaultBuckets,
+	}, []string{"method", "route"})
+)
+
+// Handler returns a handler serving the Default registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Default, promhttp.HandlerOpts{})
+}
+
+// Transport returns a transport that records APIRequests and APIRequestDuration for every request sent through next.
+func Transport(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		endpoint := Endpoint(req.URL.Path)
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		APIRequestDuration.WithLabelValues(req.Method, endpoint).Observe(time.Since(start).Seconds())
+
+		status := "error"
+		if err == nil {
+			status = strconv.Itoa(resp.StatusCode)
+		}
+		APIRequests.WithLabelValues(req.Method, endpoint, status).Inc()
+		return resp, err
+	})
+}
+
+// identifierAfter lists path segments followed by object names or IDs, and the placeholders used for
+// them. Steve paths of namespaced resources carry the namespace before the name, e.g.
+// /v1/management.cattle.io.projects/{cluster}/{project}.
+var identifierAfter = map[string][]string{
+	"clusters":                        {"{cluster}"},
+	"projects":                        {"{project}"},
+	"namespaces":                      {"{namespace}"},
+	"settings":                        {"{setting}"},
+	"tokens":                          {"{token}"},
+	"management.cattle.io.projects":   {"{cluster}", "{project}"},
+	"provisioning.cattle.io.clusters": {"{namespace}", "{cluster}"},
+}
+
+// Endpoint replaces object names and IDs in a Rancher API path with placeholders, e.g.
+// /k8s/clusters/c-m-1234/v1/namespaces/monitoring becomes /k8s/clusters/{cluster}/v1/namespaces/{namespace},
+// so the number of label values stays bounded.
+func Endpoint(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i < len(segments); i++ {
+		placeholders := identifierAfter[segments[i]]
+		for j, placeholder := range placeholders {
+			if i+1+j < len(segments) {
+				segments[i+1+j] = placeholder
+			}
+		}
+		i += len(placeholders)
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// Middleware records HTTPRequests and HTTPRequestDuration for a handler registered under route.
+func Middleware(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r)
+		HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+		HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(recorder.status)).Inc()
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// ListenAndServe serves the Default registry on addr under /metrics until ctx is cancelled.
+func ListenAndServe(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", Handler())
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
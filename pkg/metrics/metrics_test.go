@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"/v3/clusters":                           "/v3/clusters",
+		"/v3/clusters/c-m-1234":                  "/v3/clusters/{cluster}",
+		"/v3/projects":                           "/v3/projects",
+		"/v3/settings/cacerts":                   "/v3/settings/{setting}",
+		"/k8s/clusters/c-m-1/v1/namespaces":      "/k8s/clusters/{cluster}/v1/namespaces",
+		"/k8s/clusters/c-m-1/v1/namespaces/mon/": "/k8s/clusters/{cluster}/v1/namespaces/{namespace}",
+
+		"/v1/management.cattle.io.projects":                         "/v1/management.cattle.io.projects",
+		"/v1/management.cattle.io.projects/c-m-1":                   "/v1/management.cattle.io.projects/{cluster}",
+		"/v1/management.cattle.io.projects/c-m-1/p-x2v4":            "/v1/management.cattle.io.projects/{cluster}/{project}",
+		"/v1/provisioning.cattle.io.clusters":                       "/v1/provisioning.cattle.io.clusters",
+		"/v1/provisioning.cattle.io.clusters/fleet-local":           "/v1/provisioning.cattle.io.clusters/{namespace}",
+		"/v1/provisioning.cattle.io.clusters/fleet-default/edge-01": "/v1/provisioning.cattle.io.clusters/{namespace}/{cluster}",
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, Endpoint(path), path)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: Transport(http.DefaultTransport)}
+	counter := APIRequests.WithLabelValues("GET", "/v3/clusters/{cluster}", "418")
+	before := testutil.ToFloat64(counter)
+
+	resp, err := client.Get(upstream.URL + "/v3/clusters/c-m-42")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+
+	metricsSrv := httptest.NewServer(Handler())
+	defer metricsSrv.Close()
+	resp, err = http.Get(metricsSrv.URL)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), `rancher_projects_api_requests_total{endpoint="/v3/clusters/{cluster}",method="GET",status="418"}`)
+	assert.Contains(t, string(body), `rancher_projects_api_request_duration_seconds_count{endpoint="/v3/clusters/{cluster}",method="GET"}`)
+}
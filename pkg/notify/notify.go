@@ -0,0 +1,173 @@
+// Package notify sends webhook notifications about changes made by rancher-projects.
+//
+// Payloads are plain JSON events by default, or Slack or Microsoft Teams messages. When a
+// webhook has a secret, the body is signed with HMAC-SHA256 and the hex digest is sent in the
+// X-Rancher-Projects-Signature header as "sha256=<digest>", so receivers can verify it.
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/logging"
+)
+
+var logger = logging.SetupLogging()
+
+// Event types.
+const (
+	ProjectCreated    = "project.created"
+	NamespaceCreated  = "namespace.created"
+	NamespaceAssigned = "namespace.assigned"
+	ClusterFailed     = "cluster.failed"
+)
+
+// Headers set on every webhook request.
+const (
+	SignatureHeader = "X-Rancher-Projects-Signature"
+	EventHeader     = "X-Rancher-Projects-Event"
+)
+
+// Event describes a change made by the tool or a failure in a cluster.
+type Event struct {
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	Cluster   string    `json:"cluster,omitempty"`
+	ClusterID string    `json:"clusterId,omitempty"`
+	Project   string    `json:"project,omitempty"`
+	ProjectID string    `json:"projectId,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Message returns a one line human readable description of the event.
+func (e Event) Message() string {
+	cluster := e.Cluster
+	if cluster == "" {
+		cluster = e.ClusterID
+	}
+	project := e.Project
+	if project == "" {
+		project = e.ProjectID
+	}
+
+	switch e.Type {
+	case ProjectCreated:
+		return fmt.Sprintf("Created project %s in cluster %s", project, cluster)
+	case NamespaceCreated:
+		return fmt.Sprintf("Created namespace %s in cluster %s", e.Namespace, cluster)
+	case NamespaceAssigned:
+		return fmt.Sprintf("Assigned namespace %s to project %s in cluster %s", e.Namespace, project, cluster)
+	case ClusterFailed:
+		return fmt.Sprintf("Failed to process cluster %s: %s", cluster, e.Error)
+	}
+	return e.Type
+}
+
+// Client is the HTTP client used to deliver webhooks.
+var Client = &http.Client{Timeout: 10 * time.Second}
+
+// Send delivers the event to every webhook. Delivery failures are logged and do not affect
+// the change that triggered the event.
+func Send(webhooks []config.Webhook, e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	for _, w := range webhooks {
+		if err := deliver(w, e); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to send %s notification to %s: %v", e.Type, w.URL, err))
+		}
+	}
+}
+
+func deliver(w config.Webhook, e Event) error {
+	body, err := Payload(w.Format, e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, e.Type)
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+
+	logger.Debug(fmt.Sprintf("Sending %s notification to %s", e.Type, w.URL))
+	resp, err := Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Payload encodes the event in the given webhook format.
+func Payload(format string, e Event) ([]byte, error) {
+	var payload interface{}
+	switch format {
+	case "", config.WebhookFormatJSON:
+		payload = e
+	case config.WebhookFormatSlack:
+		payload = map[string]string{"text": e.Message()}
+	case config.WebhookFormatTeams:
+		payload = teamsMessage(e)
+	default:
+		return nil, fmt.Errorf("unsupported webhook format %q", format)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", e.Type, err)
+	}
+	return body, nil
+}
+
+// teamsMessage formats the event as an Office 365 connector card.
+func teamsMessage(e Event) map[string]interface{} {
+	color := "2E7D32"
+	if e.Type == ClusterFailed {
+		color = "C62828"
+	}
+
+	var facts []map[string]string
+	for _, f := range [][2]string{
+		{"Cluster", e.Cluster}, {"Cluster ID", e.ClusterID}, {"Project", e.Project},
+		{"Project ID", e.ProjectID}, {"Namespace", e.Namespace}, {"Error", e.Error},
+	} {
+		if f[1] != "" {
+			facts = append(facts, map[string]string{"name": f[0], "value": f[1]})
+		}
+	}
+
+	return map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    e.Message(),
+		"themeColor": color,
+		"title":      "rancher-projects: " + e.Type,
+		"sections":   []map[string]interface{}{{"activityTitle": e.Message(), "facts": facts}},
+	}
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by the hex encoded
+// HMAC-SHA256 of body keyed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, status int) (*httptest.Server, func() []received) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []received
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, received{header: r.Header.Clone(), body: body})
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []received {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]received(nil), requests...)
+	}
+}
+
+func TestSendSignsPayload(t *testing.T) {
+	srv, requests := newReceiver(t, http.StatusNoContent)
+	event := Event{Type: NamespaceAssigned, Cluster: "prod", ClusterID: "c-1", ProjectID: "c-1:p-1", Namespace: "monitoring"}
+
+	Send([]config.Webhook{{URL: srv.URL, Secret: "s3cret"}, {URL: srv.URL}}, event)
+
+	got := requests()
+	require.Len(t, got, 2)
+	signed := got[0]
+	assert.Equal(t, NamespaceAssigned, signed.header.Get(EventHeader))
+	assert.Equal(t, "application/json", signed.header.Get("Content-Type"))
+	assert.True(t, hmac.Equal([]byte(Sign("s3cret", signed.body)), []byte(signed.header.Get(SignatureHeader))))
+	assert.Empty(t, got[1].header.Get(SignatureHeader))
+
+	var decoded Event
+	require.NoError(t, json.Unmarshal(signed.body, &decoded))
+	assert.Equal(t, "monitoring", decoded.Namespace)
+	assert.Equal(t, "c-1:p-1", decoded.ProjectID)
+	assert.False(t, decoded.Time.IsZero())
+}
+
+func TestSendIgnoresFailures(t *testing.T) {
+	failing, _ := newReceiver(t, http.StatusInternalServerError)
+	srv, requests := newReceiver(t, http.StatusOK)
+
+	Send([]config.Webhook{{URL: failing.URL}, {URL: "http://127.0.0.1:1"}, {URL: srv.URL}}, Event{Type: ProjectCreated, ClusterID: "c-1", Project: "Team"})
+	assert.Len(t, requests(), 1)
+}
+
+func TestPayloadFormats(t *testing.T) {
+	event := Event{Type: ClusterFailed, Cluster: "prod", ClusterID: "c-1", Error: "boom"}
+
+	body, err := Payload(config.WebhookFormatSlack, event)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"text":"Failed to process cluster prod: boom"}`, string(body))
+
+	body, err = Payload(config.WebhookFormatTeams, event)
+	require.NoError(t, err)
+	var card map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &card))
+	assert.Equal(t, "MessageCard", card["@type"])
+	assert.Equal(t, "C62828", card["themeColor"])
+	assert.Equal(t, "Failed to process cluster prod: boom", card["summary"])
+
+	_, err = Payload("xml", event)
+	assert.Error(t, err)
+}
+
+func TestSign(t *testing.T) {
+	// Reference value from: printf 'payload' | openssl dgst -sha256 -hmac key
+	assert.Equal(t, "sha256=5d98b45c90a207fa998ce639fea6f02ecc8cc3f36fef81d694fb856b4d0a28ca", Sign("key", []byte("payload")))
+}
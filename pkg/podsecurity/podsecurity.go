@@ -0,0 +1,70 @@
+// Package podsecurity maps the Pod Security level of a project to the Pod Security Admission
+// labels of its namespaces.
+//
+// A project's level is stored in the Annotation of the Rancher project. Every namespace of the
+// project carries the level in the enforce, audit and warn labels, e.g.
+//
+//	pod-security.kubernetes.io/enforce: restricted
+//	pod-security.kubernetes.io/audit: restricted
+//	pod-security.kubernetes.io/warn: restricted
+package podsecurity
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Pod Security Standards levels.
+const (
+	Privileged = "privileged"
+	Baseline   = "baseline"
+	Restricted = "restricted"
+)
+
+// Levels lists the valid levels from least to most restrictive.
+var Levels = []string{Privileged, Baseline, Restricted}
+
+// Annotation is the project annotation holding the project's level.
+const Annotation = "rancher-projects.supporttools.io/pod-security"
+
+// LabelPrefix is the prefix of the Pod Security Admission namespace labels.
+const LabelPrefix = "pod-security.kubernetes.io/"
+
+// Modes are the Pod Security Admission modes set to the project's level.
+var Modes = []string{"enforce", "audit", "warn"}
+
+// Valid reports whether level is one of Levels.
+func Valid(level string) bool {
+	for _, l := range Levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// Labels returns the namespace labels for level.
+func Labels(level string) map[string]string {
+	labels := make(map[string]string, len(Modes))
+	for _, mode := range Modes {
+		labels[LabelPrefix+mode] = level
+	}
+	return labels
+}
+
+// Differences describes, sorted by label, every label of a namespace that does not match level.
+// It returns nil when the labels match.
+func Differences(labels map[string]string, level string) []string {
+	var differences []string
+	for key, want := range Labels(level) {
+		have, ok := labels[key]
+		switch {
+		case !ok:
+			differences = append(differences, fmt.Sprintf("%s is missing, want %s", key, want))
+		case have != want:
+			differences = append(differences, fmt.Sprintf("%s is %s, want %s", key, have, want))
+		}
+	}
+	sort.Strings(differences)
+	return differences
+}
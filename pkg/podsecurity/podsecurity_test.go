@@ -0,0 +1,36 @@
+package podsecurity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	for _, level := range Levels {
+		assert.True(t, Valid(level), level)
+	}
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("Restricted"))
+}
+
+func TestLabels(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"pod-security.kubernetes.io/enforce": "baseline",
+		"pod-security.kubernetes.io/audit":   "baseline",
+		"pod-security.kubernetes.io/warn":    "baseline",
+	}, Labels(Baseline))
+}
+
+func TestDifferences(t *testing.T) {
+	assert.Nil(t, Differences(Labels(Restricted), Restricted))
+
+	labels := Labels(Restricted)
+	labels["pod-security.kubernetes.io/enforce"] = "baseline"
+	delete(labels, "pod-security.kubernetes.io/warn")
+	labels["pod-security.kubernetes.io/enforce-version"] = "latest"
+	assert.Equal(t, []string{
+		"pod-security.kubernetes.io/enforce is baseline, want restricted",
+		"pod-security.kubernetes.io/warn is missing, want restricted",
+	}, Differences(labels, Restricted))
+}
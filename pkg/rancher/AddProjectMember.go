@@ -0,0 +1,55 @@
+package rancher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/supporttools/rancher-projects/pkg/audit"
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// AddProjectMember creates a project role template binding granting a user or group a role in a project.
+func AddProjectMember(cfg *config.Config, clusterID string, member ProjectMember) (err error) {
+	principal := member.UserPrincipalID
+	if principal == "" {
+		principal = member.GroupPrincipalID
+	}
+	logger.Info(fmt.Sprintf("Granting %s role %s in project %s...", principal, member.RoleTemplateID, member.ProjectID))
+
+	member.ID = ""
+	payload, err := json.Marshal(member)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to marshal project member: %v", err))
+		return fmt.Errorf("failed to marshal project member: %v", err)
+	}
+
+	url := fmt.Sprintf("%s/v3/projectroletemplatebindings", cfg.RancherServerURL)
+	req, err := NewRequest(cfg, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	entry := audit.Entry{Action: audit.ActionAddProjectMember, ClusterID: clusterID, Object: "project/" + member.ProjectID, After: fmt.Sprintf("%s=%s", principal, member.RoleTemplateID)}
+	defer func() { auditChange(cfg, &entry, err) }()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		logger.Error(fmt.Sprintf("Failed to add member to project %s, status code: %d", member.ProjectID, resp.StatusCode))
+		return fmt.Errorf("failed to add member to project %s, status code: %d", member.ProjectID, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package rancher
+
+import (
+	"fmt"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// ApplyProjectTemplate makes a project match a project template: the description, labels, quotas
+// and default limits of the template are set, the Pod Security level is recorded, missing members
+// are added and the template's namespaces are created when missing and assigned to the project.
+// Settings the template leaves empty are not touched and nothing is removed, so applying a
+// template again is safe and brings existing projects in line with template changes.
+func ApplyProjectTemplate(cfg *config.Config, clusterID, projectID string, tmpl config.ProjectTemplate) error {
+	logger.Info(fmt.Sprintf("Applying project template to project %s in cluster %s...", projectID, clusterID))
+
+	project, err := GetProject(cfg, projectID)
+	if err != nil {
+		return err
+	}
+	source := Project{
+		Description:                   tmpl.Description,
+		Labels:                        tmpl.Labels,
+		ResourceQuota:                 tmpl.ResourceQuota,
+		NamespaceDefaultResourceQuota: tmpl.NamespaceDefaultResourceQuota,
+		ContainerDefaultResourceLimit: tmpl.ContainerDefaultResourceLimit,
+	}
+	if source.Description == "" {
+		source.Description = project.Description
+	}
+	if changes := projectChanges(source, *project); len(changes) > 0 {
+		if err := UpdateProject(cfg, clusterID, projectID, changes); err != nil {
+			return err
+		}
+	}
+
+	// The level is set before the namespaces are assigned so they get their labels.
+	if tmpl.PodSecurity != "" {
+		if err := SetProjectPodSecurity(cfg, clusterID, projectID, tmpl.PodSecurity); err != nil {
+			return err
+		}
+	}
+
+	members := make([]ProjectMember, 0, len(tmpl.Members))
+	for _, m := range tmpl.Members {
+		members = append(members, ProjectMember{RoleTemplateID: m.Role, UserPrincipalID: m.User, GroupPrincipalID: m.Group})
+	}
+	added, err := replicateMembers(cfg, clusterID, projectID, members)
+	if err != nil {
+		return err
+	}
+	if added > 0 {
+		logger.Info(fmt.Sprintf("Added %d member(s) to project %s", added, projectID))
+	}
+
+	if len(tmpl.Namespaces) == 0 {
+		return nil
+	}
+	namespaces, err := ListNamespaces(cfg, clusterID)
+	if err != nil {
+		return err
+	}
+	current := map[string]string{}
+	for _, ns := range namespaces {
+		current[ns.Name] = ns.ProjectID()
+	}
+	for _, name := range tmpl.Namespaces {
+		have, exists := current[name]
+		created := false
+		if !exists {
+			if created, err = createNamespace(cfg, clusterID, name); err != nil {
+				return err
+			}
+		}
+		if have != projectID {
+			if err := AssignNamespaceToProject(cfg, clusterID, name, projectID); err != nil {
+				return err
+			}
+		}
+		if created && cfg.CreateNetworkPolicies {
+			if err := CreateDefaultNetworkPolicies(cfg, clusterID, name, projectID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
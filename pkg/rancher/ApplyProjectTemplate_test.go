@@ -0,0 +1,71 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/podsecurity"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestMainProjectAppliesTemplate(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	quota := map[string]interface{}{"limit": map[string]interface{}{"pods": "200"}}
+	cfg.ProjectName = "Team"
+	cfg.CreateProject = true
+	cfg.ProjectTemplate = "team"
+	cfg.ProjectTemplates = map[string]config.ProjectTemplate{"team": {
+		Description:                   "Team project",
+		Labels:                        map[string]string{"tier": "standard"},
+		ResourceQuota:                 quota,
+		NamespaceDefaultResourceQuota: map[string]interface{}{"limit": map[string]interface{}{"pods": "50"}},
+		PodSecurity:                   podsecurity.Baseline,
+		Members:                       []config.TemplateMember{{Role: "project-owner", Group: "github_team://1234"}},
+		Namespaces:                    []string{"ci", "existing"},
+	}}
+	srv.AddNamespace(cluster.ID, "existing", "")
+
+	require.NoError(t, MainProject(cfg, cluster.ID))
+
+	project, ok := srv.Project(cluster.ID, "Team")
+	require.True(t, ok)
+	assert.Equal(t, "Team project", project.Description)
+	assert.Equal(t, "standard", project.Labels["tier"])
+	assert.True(t, sameJSON(quota, project.ResourceQuota))
+	assert.Equal(t, "baseline", project.Annotations[podsecurity.Annotation])
+	members := srv.ProjectMembers(project.ID)
+	require.Len(t, members, 1)
+	assert.Equal(t, "project-owner", members[0].RoleTemplateID)
+	assert.Equal(t, "github_team://1234", members[0].GroupPrincipalID)
+	for _, name := range []string{"ci", "existing"} {
+		ns, ok := srv.Namespace(cluster.ID, name)
+		require.True(t, ok, name)
+		assert.Equal(t, project.ID, ns.ProjectID(), name)
+		assert.Equal(t, "baseline", ns.Labels["pod-security.kubernetes.io/enforce"], name)
+	}
+
+	// Applying the template again changes nothing.
+	updates := srv.CountRequests("PUT", "")
+	require.NoError(t, MainProject(cfg, cluster.ID))
+	assert.Equal(t, updates, srv.CountRequests("PUT", ""))
+	assert.Len(t, srv.ProjectMembers(project.ID), 1)
+}
+
+func TestApplyProjectTemplateKeepsUnsetFields(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	project := srv.AddProject(cluster.ID, "Team")
+	srv.UpdateProject(cluster.ID, "Team", func(p *ranchertest.Project) {
+		p.Description = "Hand written"
+		p.Labels = map[string]string{"owner": "team"}
+	})
+
+	tmpl := config.ProjectTemplate{Labels: map[string]string{"tier": "gold"}}
+	require.NoError(t, ApplyProjectTemplate(cfg, cluster.ID, project.ID, tmpl))
+
+	updated, _ := srv.Project(cluster.ID, "Team")
+	assert.Equal(t, "Hand written", updated.Description)
+	assert.Equal(t, map[string]string{"owner": "team", "tier": "gold"}, updated.Labels)
+}
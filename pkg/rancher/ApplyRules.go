@@ -0,0 +1,111 @@
+package rancher
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/rules"
+)
+
+// RuleSummary counts what ApplyRules found and changed.
+type RuleSummary struct {
+	Clusters        int           `json:"clusters"`
+	Matched         int           `json:"matched"`
+	Assigned        int           `json:"assigned"`
+	ProjectsCreated int           `json:"projectsCreated"`
+	Skipped         []string      `json:"skipped,omitempty"`
+	Errors          []string      `json:"errors,omitempty"`
+	Duration        time.Duration `json:"duration"`
+}
+
+// String formats the summary as a single log line.
+func (s *RuleSummary) String() string {
+	return fmt.Sprintf("clusters=%d matched=%d assigned=%d projectsCreated=%d skipped=%d errors=%d duration=%s",
+		s.Clusters, s.Matched, s.Assigned, s.ProjectsCreated, len(s.Skipped), len(s.Errors), s.Duration.Round(time.Millisecond))
+}
+
+// ApplyRules evaluates the rules against every namespace in the selected clusters and assigns
+// matching namespaces to the rule's project. Namespaces already in a project are only moved by
+// rules with reassign set. Missing projects are created with --create-project and skipped
+// otherwise. Failures in one cluster are recorded and do not stop the others.
+func ApplyRules(cfg *config.Config, r *rules.Rules) (*RuleSummary, error) {
+	start := time.Now()
+	summary := &RuleSummary{}
+
+	clusters, err := SelectClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to select clusters: %v", err))
+		return nil, fmt.Errorf("failed to select clusters: %v", err)
+	}
+
+	for _, cluster := range clusters {
+		summary.Clusters++
+		clusterCfg := *cfg
+		clusterCfg.ClusterName = cluster.Name
+		if err := applyRulesToCluster(&clusterCfg, cluster, r, summary); err != nil {
+			logger.Error(fmt.Sprintf("Failed to apply rules to cluster %s: %v", cluster.Name, err))
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", cluster.Name, err))
+		}
+	}
+
+	summary.Duration = time.Since(start)
+	return summary, nil
+}
+
+// applyRulesToCluster applies the rules to the namespaces of one cluster and adds the results to summary.
+func applyRulesToCluster(cfg *config.Config, cluster ClusterRef, r *rules.Rules, summary *RuleSummary) error {
+	logger.Info(fmt.Sprintf("Applying %d rule(s) to cluster %s (%s)...", len(r.Rules), cluster.Name, cluster.ID))
+
+	projects, err := ListProjects(cfg, cluster.ID)
+	if err != nil {
+		return err
+	}
+	namespaces, err := ListNamespaces(cfg, cluster.ID)
+	if err != nil {
+		return err
+	}
+	projectIDs := map[string]string{}
+	for _, p := range projects {
+		projectIDs[p.Name] = p.Id
+	}
+
+	for _, ns := range namespaces {
+		rule := r.Match(ns.Name, ns.Labels)
+		if rule == nil {
+			continue
+		}
+		summary.Matched++
+
+		projectID, ok := projectIDs[rule.Project]
+		if ok && ns.ProjectID() == projectID {
+			continue
+		}
+		if ns.ProjectID() != "" && !rule.Reassign {
+			logger.Debug(fmt.Sprintf("Namespace %s is already in project %s, not moving it to %s", ns.Name, ns.ProjectID(), rule.Project))
+			continue
+		}
+		if !ok {
+			if !cfg.CreateProject {
+				reason := fmt.Sprintf("%s: project %s for namespace %s does not exist", cluster.Name, rule.Project, ns.Name)
+				logger.Warn(fmt.Sprintf("Skipping %s; set --create-project to create it", reason))
+				summary.Skipped = append(summary.Skipped, reason)
+				continue
+			}
+			if err := CreateProject(cfg, cluster.ID, rule.Project); err != nil {
+				return err
+			}
+			summary.ProjectsCreated++
+			if projectID, err = GetProjectInfo(cfg, cluster.ID, rule.Project); err != nil {
+				return err
+			}
+			projectIDs[rule.Project] = projectID
+		}
+
+		if err := AssignNamespaceToProject(cfg, cluster.ID, ns.Name, projectID); err != nil {
+			return err
+		}
+		summary.Assigned++
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+	"github.com/supporttools/rancher-projects/pkg/rules"
+)
+
+func TestApplyRules(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	teamA := srv.AddProject(cluster.ID, "TeamA")
+	other := srv.AddProject(cluster.ID, "Other")
+	srv.AddNamespace(cluster.ID, "team-a-dev", "")
+	srv.AddNamespace(cluster.ID, "team-a-prod", other.ID)
+	srv.AddNamespace(cluster.ID, "checkout", "")
+	srv.UpdateNamespace(cluster.ID, "checkout", func(ns *ranchertest.Namespace) {
+		ns.Labels = map[string]string{"argocd.argoproj.io/instance": "payments"}
+	})
+	srv.AddNamespace(cluster.ID, "legacy-app", other.ID)
+	srv.AddNamespace(cluster.ID, "unrelated", "")
+
+	r := &rules.Rules{Rules: []rules.Rule{
+		{Namespace: "^team-a-", Project: "TeamA"},
+		{MatchLabels: map[string]string{"argocd.argoproj.io/instance": "payments"}, Project: "Payments"},
+		{Namespace: "^legacy-", Project: "TeamA", Reassign: true},
+	}}
+	require.NoError(t, r.Validate())
+
+	// Without --create-project the namespace for the missing project is skipped.
+	summary, err := ApplyRules(cfg, r)
+	require.NoError(t, err)
+	assert.Empty(t, summary.Errors)
+	assert.Equal(t, 4, summary.Matched)
+	assert.Equal(t, 2, summary.Assigned)
+	assert.Len(t, summary.Skipped, 1)
+	for name, expected := range map[string]string{
+		"team-a-dev":  teamA.ID,
+		"team-a-prod": other.ID, // already in a project and the rule does not reassign
+		"legacy-app":  teamA.ID,
+		"checkout":    "",
+		"unrelated":   "",
+	} {
+		ns, _ := srv.Namespace(cluster.ID, name)
+		assert.Equal(t, expected, ns.ProjectID(), name)
+	}
+
+	cfg.CreateProject = true
+	summary, err = ApplyRules(cfg, r)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.ProjectsCreated)
+	assert.Equal(t, 1, summary.Assigned)
+	payments, ok := srv.Project(cluster.ID, "Payments")
+	require.True(t, ok)
+	ns, _ := srv.Namespace(cluster.ID, "checkout")
+	assert.Equal(t, payments.ID, ns.ProjectID())
+
+	// Nothing left to do.
+	summary, err = ApplyRules(cfg, r)
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.Assigned)
+	assert.Equal(t, 0, summary.ProjectsCreated)
+}
@@ -2,36 +2,38 @@ package rancher
 
 import (
 	"bytes"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"time"
+	"strings"
 
+	"github.com/supporttools/rancher-projects/pkg/audit"
 	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/metrics"
+	"github.com/supporttools/rancher-projects/pkg/notify"
+	"github.com/supporttools/rancher-projects/pkg/podsecurity"
 )
 
-// AssignNamespaceToProject updates the project ID associated with a namespace in Rancher.
+// AssignNamespaceToProject updates the project ID associated with a namespace in Rancher. When
+// the project has a Pod Security level the namespace gets the matching Pod Security Admission labels.
 // It returns an error in case of failure else nil.
-func AssignNamespaceToProject(cfg *config.Config, clusterID, namespace, projectID string) error {
-	logger.Info(fmt.Sprintf("Assigning namespace %s to project %s in cluster %s...", namespace, cfg.ProjectName, clusterID))
+func AssignNamespaceToProject(cfg *config.Config, clusterID, namespace, projectID string) (err error) {
+	logger.Info(fmt.Sprintf("Assigning namespace %s to project %s in cluster %s...", namespace, projectID, clusterID))
 
 	url := fmt.Sprintf("%s/k8s/clusters/%s/v1/namespaces/%s", cfg.RancherServerURL, clusterID, namespace)
 	logger.Debug(fmt.Sprintf("Generated URL for namespace update: %s", url))
 
 	// Use http.NoBody instead of nil for requests with no body.
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create HTTP GET request: %v", err))
 		return fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	authHeader := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", cfg.RancherAccessKey, cfg.RancherSecretKey)))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", authHeader))
-
-	client := &http.Client{
-		Timeout: time.Second * 10, // 10 seconds timeout
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
 	}
 
 	logger.Debug("Sending GET request to fetch namespace details...")
@@ -48,22 +50,64 @@ func AssignNamespaceToProject(cfg *config.Config, clusterID, namespace, projectI
 	}
 
 	logger.Debug("Decoding JSON response from Rancher API...")
-	var namespaceData struct {
-		Metadata struct {
-			Annotations struct {
-				ProjectID string `json:"field.cattle.io/projectId"`
-			} `json:"annotations"`
-		} `json:"metadata"`
-	}
-
+	// Decode the whole object so the PUT below does not drop any other metadata.
+	var namespaceData map[string]interface{}
 	err = json.NewDecoder(resp.Body).Decode(&namespaceData)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to decode JSON response: %v", err))
 		return fmt.Errorf("failed to decode JSON response: %w", err)
 	}
 
-	logger.Info(fmt.Sprintf("Current project ID for namespace %s: %s", namespace, namespaceData.Metadata.Annotations.ProjectID))
-	namespaceData.Metadata.Annotations.ProjectID = projectID
+	metadata, _ := namespaceData["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		namespaceData["metadata"] = metadata
+	}
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if annotations == nil {
+		annotations = map[string]interface{}{}
+		metadata["annotations"] = annotations
+	}
+	labels, _ := metadata["labels"].(map[string]interface{})
+	if labels == nil {
+		labels = map[string]interface{}{}
+		metadata["labels"] = labels
+	}
+
+	// The project is only needed for its Pod Security level; Rancher itself rejects unknown projects.
+	project, err := GetProject(cfg, projectID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		logger.Error(fmt.Sprintf("Failed to get project %s: %v", projectID, err))
+		return fmt.Errorf("failed to get project %s: %w", projectID, err)
+	}
+
+	logger.Info(fmt.Sprintf("Current project ID for namespace %s: %v", namespace, annotations[ProjectIDAnnotation]))
+	previous, _ := annotations[ProjectIDAnnotation].(string)
+	entry := audit.Entry{Action: audit.ActionAssignNamespace, ClusterID: clusterID, Object: "namespace/" + namespace, Before: previous, After: projectID}
+	defer func() { auditChange(cfg, &entry, err) }()
+
+	// The annotation holds the full <cluster>:<project> ID, the label only the project part.
+	wantLabels := map[string]string{ProjectIDAnnotation: projectID[strings.Index(projectID, ":")+1:]}
+	level := projectPodSecurity(project)
+	if level != "" {
+		for k, v := range podsecurity.Labels(level) {
+			wantLabels[k] = v
+		}
+	}
+
+	if previous == projectID && hasLabels(labels, wantLabels) {
+		logger.Info(fmt.Sprintf("Namespace %s is already assigned to project %s", namespace, projectID))
+		entry.Outcome = audit.OutcomeUnchanged
+		return nil
+	}
+
+	annotations[ProjectIDAnnotation] = projectID
+	if level != "" {
+		logger.Info(fmt.Sprintf("Applying Pod Security level %s of project %s to namespace %s", level, projectID, namespace))
+	}
+	for k, v := range wantLabels {
+		labels[k] = v
+	}
 
 	payload, err := json.Marshal(namespaceData)
 	if err != nil {
@@ -72,16 +116,13 @@ func AssignNamespaceToProject(cfg *config.Config, clusterID, namespace, projectI
 	}
 
 	logger.Debug(fmt.Sprintf("Generated payload for namespace update: %s", string(payload)))
-	req, err = http.NewRequest("PUT", url, bytes.NewBuffer(payload))
+	req, err = NewRequest(cfg, "PUT", url, bytes.NewBuffer(payload))
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create HTTP PUT request: %v", err))
 		return fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", authHeader))
-
-	logger.Info(fmt.Sprintf("Sending PUT request to update namespace %s to project %s...", namespace, cfg.ProjectName))
+	logger.Info(fmt.Sprintf("Sending PUT request to update namespace %s to project %s...", namespace, projectID))
 	resp, err = client.Do(req)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to send HTTP PUT request: %v", err))
@@ -94,6 +135,26 @@ func AssignNamespaceToProject(cfg *config.Config, clusterID, namespace, projectI
 		return fmt.Errorf("failed to assign namespace to project, status code: %d", resp.StatusCode)
 	}
 
-	logger.Info(fmt.Sprintf("Successfully assigned namespace %s to project %s", namespace, cfg.ProjectName))
+	logger.Info(fmt.Sprintf("Successfully assigned namespace %s to project %s", namespace, projectID))
+	metrics.NamespacesAssigned.WithLabelValues(clusterID).Inc()
+	Notify(cfg, notify.Event{Type: notify.NamespaceAssigned, ClusterID: clusterID, Namespace: namespace, ProjectID: projectID})
 	return nil
 }
+
+// projectPodSecurity returns the Pod Security level of a project, or "" when it has none.
+func projectPodSecurity(project *Project) string {
+	if project == nil {
+		return ""
+	}
+	return project.Annotations[podsecurity.Annotation]
+}
+
+// hasLabels reports whether labels, as decoded from a namespace, already hold every wanted value.
+func hasLabels(labels map[string]interface{}, want map[string]string) bool {
+	for k, v := range want {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
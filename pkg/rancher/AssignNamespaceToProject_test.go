@@ -0,0 +1,58 @@
+package rancher
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/audit"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestAssignNamespaceToProjectKeepsMetadata(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	project := srv.AddProject(cluster.ID, "TeamA")
+	srv.AddNamespace(cluster.ID, "team-a", "")
+	srv.UpdateNamespace(cluster.ID, "team-a", func(ns *ranchertest.Namespace) {
+		ns.Labels["team"] = "a"
+		ns.Annotations["owner"] = "team-a@example.com"
+	})
+
+	require.NoError(t, AssignNamespaceToProject(cfg, cluster.ID, "team-a", project.ID))
+	require.NoError(t, VerifyProjectAssignment(cfg, cluster.ID, "team-a", project.ID))
+
+	ns, _ := srv.Namespace(cluster.ID, "team-a")
+	assert.Equal(t, project.ID, ns.Annotations[ProjectIDAnnotation])
+	assert.Equal(t, project.ID[len(cluster.ID)+1:], ns.Labels[ProjectIDAnnotation])
+	assert.Equal(t, "a", ns.Labels["team"])
+	assert.Equal(t, "team-a@example.com", ns.Annotations["owner"])
+}
+
+func TestAssignNamespaceToProjectErrors(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+
+	assert.ErrorContains(t, AssignNamespaceToProject(cfg, cluster.ID, "missing", "x:p-1"), "status code: 404")
+
+	srv.AddNamespace(cluster.ID, "team-a", "")
+	srv.Fail("PUT", "/k8s/clusters/", http.StatusForbidden)
+	assert.ErrorContains(t, AssignNamespaceToProject(cfg, cluster.ID, "team-a", "x:p-1"), "status code: 403")
+	assert.Error(t, VerifyProjectAssignment(cfg, cluster.ID, "team-a", "x:p-1"))
+}
+
+func TestAssignNamespaceToProjectUnchanged(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	cfg.AuditFile = filepath.Join(t.TempDir(), "audit.jsonl")
+	project := srv.AddProject(cluster.ID, "TeamA")
+	srv.AddNamespace(cluster.ID, "team-a", project.ID)
+
+	require.NoError(t, AssignNamespaceToProject(cfg, cluster.ID, "team-a", project.ID))
+	assert.Zero(t, srv.CountRequests("PUT", "/k8s/clusters/"), "a namespace already in the project is not updated")
+
+	entries, err := audit.Read(cfg.AuditFile)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, audit.OutcomeUnchanged, entries[0].Outcome)
+}
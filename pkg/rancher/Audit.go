@@ -0,0 +1,140 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/supporttools/rancher-projects/pkg/audit"
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+var (
+	auditMu   sync.Mutex
+	auditLogs = map[string]*audit.Log{}
+	operators = map[string]string{}
+)
+
+// OpenAuditLog opens the audit log configured with --audit-log, so a path that cannot be
+// written is reported before any change is made. Later calls return the same log.
+func OpenAuditLog(cfg *config.Config) (*audit.Log, error) {
+	if cfg.AuditFile == "" {
+		return nil, nil
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	if l, ok := auditLogs[cfg.AuditFile]; ok {
+		return l, nil
+	}
+	l, err := audit.Open(cfg.AuditFile)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to open audit log: %v", err))
+		return nil, err
+	}
+	auditLogs[cfg.AuditFile] = l
+	return l, nil
+}
+
+// Audit appends e to the audit log, filling in the operator, caller, server and cluster name
+// from cfg. Nothing is written while replaying a cassette, since no change is actually made.
+// Failures to write are logged as errors; the change itself has already happened.
+func Audit(cfg *config.Config, e audit.Entry) {
+	if cfg.AuditFile == "" || cfg.ReplayFile != "" {
+		return
+	}
+	l, err := OpenAuditLog(cfg)
+	if err != nil {
+		return
+	}
+
+	e.Operator = Operator(cfg)
+	e.Caller = cfg.Caller
+	e.Server = cfg.RancherServerURL
+	if e.Cluster == "" && cfg.ClusterName != "" {
+		e.Cluster = cfg.ClusterName
+	}
+	if err := l.Write(e); err != nil {
+		logger.Error(fmt.Sprintf("Failed to write audit entry for %s %s: %v", e.Action, e.Object, err))
+	}
+}
+
+// auditChange records entry with the outcome of err, unless an outcome has already been set.
+// It is deferred right before the request that makes the change.
+func auditChange(cfg *config.Config, entry *audit.Entry, err error) {
+	if entry.Outcome == "" {
+		entry.Outcome = audit.OutcomeSuccess
+		if err != nil {
+			entry.Outcome, entry.Error = audit.OutcomeFailure, err.Error()
+		}
+	}
+	Audit(cfg, *entry)
+}
+
+// Operator returns the identity of the Rancher user the configured credentials belong to, as
+// "<username> (<token name>)". The username is looked up once per token; when the lookup
+// fails only the token name is returned. The token secret is never included.
+func Operator(cfg *config.Config) string {
+	tokenName := cfg.RancherAccessKey
+	if cfg.RancherToken != "" {
+		tokenName, _, _ = strings.Cut(cfg.RancherToken, ":")
+	}
+	key := cfg.RancherServerURL + "|" + tokenName
+
+	auditMu.Lock()
+	operator, ok := operators[key]
+	auditMu.Unlock()
+	if ok {
+		return operator
+	}
+
+	operator = tokenName
+	if username, err := currentUsername(cfg); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to look up the user of token %s: %v", tokenName, err))
+	} else if username != "" {
+		operator = fmt.Sprintf("%s (%s)", username, tokenName)
+	}
+
+	auditMu.Lock()
+	operators[key] = operator
+	auditMu.Unlock()
+	return operator
+}
+
+// currentUsername returns the username of the user the configured credentials belong to.
+func currentUsername(cfg *config.Config) (string, error) {
+	url := fmt.Sprintf("%s/v3/users?me=true", cfg.RancherServerURL)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var users struct {
+		Data []struct {
+			Username string `json:"username"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(users.Data) == 0 {
+		return "", nil
+	}
+	return users.Data[0].Username, nil
+}
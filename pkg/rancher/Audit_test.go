@@ -0,0 +1,60 @@
+package rancher
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/audit"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestAuditLog(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	cfg.AuditFile = filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg.ProjectName = "Team"
+	cfg.Namespace = "team"
+	cfg.CreateProject = true
+	cfg.CreateNamespace = true
+	cfg.CreateKubeconfig = true
+	cfg.KubeconfigDir = t.TempDir()
+	other := srv.AddProject(cluster.ID, "Other")
+	srv.AddNamespace(cluster.ID, "team", other.ID)
+
+	require.NoError(t, SingleCluster(cfg))
+	project, ok := srv.Project(cluster.ID, "Team")
+	require.True(t, ok)
+
+	entries, err := audit.Read(cfg.AuditFile)
+	require.NoError(t, err)
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+		assert.Equal(t, ranchertest.Username+" ("+ranchertest.AccessKey+")", e.Operator)
+		assert.Equal(t, cluster.ID, e.ClusterID)
+		assert.Equal(t, cluster.Name, e.Cluster)
+		assert.NotContains(t, e.Operator, ranchertest.SecretKey)
+	}
+	require.Equal(t, []string{audit.ActionCreateProject, audit.ActionCreateNamespace, audit.ActionAssignNamespace, audit.ActionGenerateKubeconfig}, actions)
+
+	assert.Equal(t, project.ID, entries[0].After)
+	assert.Equal(t, audit.OutcomeSuccess, entries[0].Outcome)
+	assert.Equal(t, audit.OutcomeUnchanged, entries[1].Outcome)
+	assert.Equal(t, "namespace/team", entries[2].Object)
+	assert.Equal(t, other.ID, entries[2].Before)
+	assert.Equal(t, project.ID, entries[2].After)
+	assert.Equal(t, audit.OutcomeSuccess, entries[3].Outcome)
+
+	// Failed changes are recorded with their error.
+	srv.Fail("POST", "/v3/projects", http.StatusForbidden)
+	assert.Error(t, CreateProject(cfg, cluster.ID, "Denied"))
+	entries, err = audit.Read(cfg.AuditFile)
+	require.NoError(t, err)
+	last := entries[len(entries)-1]
+	assert.Equal(t, "project/Denied", last.Object)
+	assert.Equal(t, audit.OutcomeFailure, last.Outcome)
+	assert.Contains(t, last.Error, "403")
+}
@@ -0,0 +1,86 @@
+package rancher
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/metrics"
+)
+
+// BootstrapCACerts fetches the CA certificates published in Rancher's cacerts setting and trusts them
+// for all subsequent requests. The setting is read over an unverified connection without credentials,
+// so the result is checked against cfg.CAChecksum when one is configured, the same way Rancher agents do.
+func BootstrapCACerts(cfg *config.Config) error {
+	logger.Info("Bootstrapping trust from Rancher cacerts setting...")
+
+	url := fmt.Sprintf("%s/v3/settings/cacerts", cfg.RancherServerURL)
+	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
+
+	// Credentials must not be sent before the server certificate can be verified.
+	req, err := http.NewRequest("GET", url, http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, //nolint:gosec // the response is verified by checksum below
+	}
+	roundTripper, err := cassetteTransport(cfg, transport)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{
+		Transport: metrics.Transport(roundTripper),
+		Timeout:   time.Second * 10, // 10 seconds timeout
+	}
+
+	logger.Info("Sending GET request to fetch cacerts setting...")
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Failed to fetch cacerts setting, status code: %d", resp.StatusCode))
+		return fmt.Errorf("failed to fetch cacerts setting, status code: %d", resp.StatusCode)
+	}
+
+	var setting struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&setting); err != nil {
+		logger.Error(fmt.Sprintf("Failed to decode JSON response: %v", err))
+		return fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+
+	if strings.TrimSpace(setting.Value) == "" {
+		logger.Info("Rancher does not publish private CA certificates, using system trust store")
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(setting.Value))
+	checksum := hex.EncodeToString(sum[:])
+	switch {
+	case cfg.CAChecksum == "":
+		logger.Warn(fmt.Sprintf("Trusting Rancher cacerts without verification; pin it with --ca-checksum %s", checksum))
+	case !strings.EqualFold(cfg.CAChecksum, checksum):
+		logger.Error(fmt.Sprintf("Checksum mismatch for cacerts setting: expected %s, got %s", cfg.CAChecksum, checksum))
+		return fmt.Errorf("checksum mismatch for cacerts setting: expected %s, got %s", cfg.CAChecksum, checksum)
+	}
+
+	cfg.CAData = setting.Value
+	logger.Info("Successfully bootstrapped trust from Rancher cacerts setting")
+	return nil
+}
@@ -0,0 +1,64 @@
+package rancher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+func newCACertsServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	var caPEM string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/settings/cacerts":
+			assert.Empty(t, r.Header.Get("Authorization"), "credentials must not be sent while bootstrapping")
+			_ = json.NewEncoder(w).Encode(map[string]string{"value": caPEM})
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(server.Close)
+	caPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	return server, caPEM
+}
+
+func TestBootstrapCACerts(t *testing.T) {
+	server, caPEM := newCACertsServer(t)
+	sum := sha256.Sum256([]byte(caPEM))
+
+	cfg := &config.Config{RancherServerURL: server.URL, RancherToken: "token-abcde:secret"}
+
+	// Without trust the server certificate is rejected.
+	require.Error(t, VerifyAccess(cfg))
+
+	cfg.CAChecksum = hex.EncodeToString(sum[:])
+	require.NoError(t, BootstrapCACerts(cfg))
+	assert.Equal(t, caPEM, cfg.CAData)
+	assert.NoError(t, VerifyAccess(cfg))
+}
+
+func TestBootstrapCACertsChecksumMismatch(t *testing.T) {
+	server, _ := newCACertsServer(t)
+
+	cfg := &config.Config{RancherServerURL: server.URL, CAChecksum: "deadbeef"}
+	assert.ErrorContains(t, BootstrapCACerts(cfg), "checksum mismatch")
+	assert.Empty(t, cfg.CAData)
+}
+
+func TestNewHTTPClientInvalidCA(t *testing.T) {
+	_, err := NewHTTPClient(&config.Config{CAData: "not a certificate"})
+	assert.Error(t, err)
+
+	_, err = NewHTTPClient(&config.Config{ClientCertFile: "missing.crt", ClientKeyFile: "missing.key"})
+	assert.Error(t, err)
+}
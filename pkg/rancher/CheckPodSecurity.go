@@ -0,0 +1,136 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/podsecurity"
+)
+
+// PodSecurityFinding is a namespace whose Pod Security labels differ from its project's level.
+type PodSecurityFinding struct {
+	Namespace   string   `json:"namespace"`
+	Project     string   `json:"project"`
+	Level       string   `json:"level"`
+	Differences []string `json:"differences"`
+}
+
+// ClusterPodSecurity holds the findings of one cluster. Error is set when the cluster could not be inspected.
+type ClusterPodSecurity struct {
+	Cluster    string               `json:"cluster"`
+	ClusterID  string               `json:"clusterId"`
+	Namespaces []PodSecurityFinding `json:"namespaces"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// PodSecurityReport lists the namespaces whose Pod Security labels diverge from their project's
+// level in every selected cluster.
+type PodSecurityReport struct {
+	Diverged bool                 `json:"diverged"`
+	Clusters []ClusterPodSecurity `json:"clusters"`
+}
+
+// HasErrors reports whether any cluster could not be inspected.
+func (r *PodSecurityReport) HasErrors() bool {
+	for _, c := range r.Clusters {
+		if c.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPodSecurity compares the Pod Security Admission labels of every namespace in a project
+// that has a Pod Security level with that level. Namespaces outside any project, and projects
+// without a level, are not checked. Clusters that cannot be inspected are recorded in the report
+// instead of aborting the run.
+func CheckPodSecurity(cfg *config.Config) (*PodSecurityReport, error) {
+	logger.Info("Checking Pod Security labels...")
+
+	clusters, err := SelectClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to select clusters: %v", err))
+		return nil, fmt.Errorf("failed to select clusters: %v", err)
+	}
+
+	report := &PodSecurityReport{Clusters: []ClusterPodSecurity{}}
+	for _, cluster := range clusters {
+		result := ClusterPodSecurity{Cluster: cluster.Name, ClusterID: cluster.ID, Namespaces: []PodSecurityFinding{}}
+		findings, err := checkClusterPodSecurity(cfg, cluster)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to inspect cluster %s: %v", cluster.Name, err))
+			result.Error = err.Error()
+		}
+		result.Namespaces = append(result.Namespaces, findings...)
+
+		logger.Info(fmt.Sprintf("Cluster %s: %d namespace(s) with diverging Pod Security labels", cluster.Name, len(result.Namespaces)))
+		report.Diverged = report.Diverged || len(result.Namespaces) > 0
+		report.Clusters = append(report.Clusters, result)
+	}
+	return report, nil
+}
+
+// checkClusterPodSecurity returns the diverging namespaces of one cluster, sorted by name.
+func checkClusterPodSecurity(cfg *config.Config, cluster ClusterRef) ([]PodSecurityFinding, error) {
+	projects, err := ListProjects(cfg, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := ListNamespaces(cfg, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[string]Project{}
+	for _, p := range projects {
+		byID[p.Id] = p
+	}
+
+	var findings []PodSecurityFinding
+	for _, ns := range namespaces {
+		project, ok := byID[ns.ProjectID()]
+		level := project.Annotations[podsecurity.Annotation]
+		if !ok || level == "" {
+			continue
+		}
+		if differences := podsecurity.Differences(ns.Labels, level); differences != nil {
+			findings = append(findings, PodSecurityFinding{Namespace: ns.Name, Project: project.Name, Level: level, Differences: differences})
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Namespace < findings[j].Namespace })
+	return findings, nil
+}
+
+// Write prints the report as a table or, with format json, as a JSON document.
+func (r *PodSecurityReport) Write(w io.Writer, format string) error {
+	if format == config.OutputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	total := 0
+	for _, c := range r.Clusters {
+		switch {
+		case c.Error != "":
+			fmt.Fprintf(tw, "Cluster %s (%s): error: %s\n", c.Cluster, c.ClusterID, c.Error)
+			continue
+		case len(c.Namespaces) == 0:
+			fmt.Fprintf(tw, "Cluster %s (%s): all namespaces match their project's Pod Security level\n", c.Cluster, c.ClusterID)
+			continue
+		}
+		total += len(c.Namespaces)
+		fmt.Fprintf(tw, "Cluster %s (%s): %d namespace(s) diverge\n", c.Cluster, c.ClusterID, len(c.Namespaces))
+		for _, f := range c.Namespaces {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", f.Namespace, f.Project, f.Level, strings.Join(f.Differences, "; "))
+		}
+	}
+	fmt.Fprintf(tw, "%d namespace(s) in %d cluster(s) diverge from their project's Pod Security level\n", total, len(r.Clusters))
+	return tw.Flush()
+}
@@ -0,0 +1,93 @@
+package rancher
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/manifest"
+	"github.com/supporttools/rancher-projects/pkg/podsecurity"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestMainProjectAppliesPodSecurity(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	srv.AddProject(cluster.ID, "Team")
+	srv.UpdateProject(cluster.ID, "Team", func(p *ranchertest.Project) {
+		p.Annotations["owner"] = "team@example.com"
+	})
+	cfg.ProjectName = "Team"
+	cfg.Namespace = "team"
+	cfg.CreateNamespace = true
+	cfg.PodSecurity = podsecurity.Restricted
+
+	require.NoError(t, MainProject(cfg, cluster.ID))
+
+	project, _ := srv.Project(cluster.ID, "Team")
+	assert.Equal(t, map[string]string{"owner": "team@example.com", podsecurity.Annotation: "restricted"}, project.Annotations)
+	ns, ok := srv.Namespace(cluster.ID, "team")
+	require.True(t, ok)
+	assert.Nil(t, podsecurity.Differences(ns.Labels, podsecurity.Restricted))
+
+	// The level is only written once.
+	require.NoError(t, MainProject(cfg, cluster.ID))
+	assert.Equal(t, 1, srv.CountRequests("PUT", "/v3/projects/"))
+}
+
+func TestReconcileAppliesPodSecurity(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	desired := &manifest.Manifest{Projects: []manifest.Project{{Name: "Team", PodSecurity: podsecurity.Baseline, Namespaces: []string{"team"}}}}
+
+	summary, err := Reconcile(cfg, desired)
+	require.NoError(t, err)
+	assert.Empty(t, summary.Errors)
+
+	ns, _ := srv.Namespace(cluster.ID, "team")
+	assert.Equal(t, "baseline", ns.Labels["pod-security.kubernetes.io/enforce"])
+}
+
+func TestCheckPodSecurity(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	team := srv.AddProject(cluster.ID, "Team")
+	open := srv.AddProject(cluster.ID, "Open")
+	require.NoError(t, SetProjectPodSecurity(cfg, cluster.ID, team.ID, podsecurity.Restricted))
+
+	// Assigned after the level was set, so labelled.
+	srv.AddNamespace(cluster.ID, "team", "")
+	require.NoError(t, AssignNamespaceToProject(cfg, cluster.ID, "team", team.ID))
+	// Assigned before, or relabelled by hand.
+	srv.AddNamespace(cluster.ID, "team-legacy", team.ID)
+	srv.AddNamespace(cluster.ID, "team-relaxed", team.ID)
+	srv.UpdateNamespace(cluster.ID, "team-relaxed", func(ns *ranchertest.Namespace) {
+		for k, v := range podsecurity.Labels(podsecurity.Restricted) {
+			ns.Labels[k] = v
+		}
+		ns.Labels["pod-security.kubernetes.io/enforce"] = "privileged"
+	})
+	// Projects without a level and namespaces outside projects are not checked.
+	srv.AddNamespace(cluster.ID, "open", open.ID)
+	srv.AddNamespace(cluster.ID, "scratch", "")
+
+	report, err := CheckPodSecurity(cfg)
+	require.NoError(t, err)
+	assert.True(t, report.Diverged)
+	assert.False(t, report.HasErrors())
+	require.Len(t, report.Clusters, 1)
+	assert.Equal(t, []PodSecurityFinding{
+		{Namespace: "team-legacy", Project: "Team", Level: "restricted", Differences: []string{
+			"pod-security.kubernetes.io/audit is missing, want restricted",
+			"pod-security.kubernetes.io/enforce is missing, want restricted",
+			"pod-security.kubernetes.io/warn is missing, want restricted",
+		}},
+		{Namespace: "team-relaxed", Project: "Team", Level: "restricted", Differences: []string{
+			"pod-security.kubernetes.io/enforce is privileged, want restricted",
+		}},
+	}, report.Clusters[0].Namespaces)
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out, "text"))
+	assert.Contains(t, out.String(), "Cluster downstream ("+cluster.ID+"): 2 namespace(s) diverge")
+	assert.Contains(t, out.String(), "team-relaxed  Team  restricted  pod-security.kubernetes.io/enforce is privileged, want restricted")
+}
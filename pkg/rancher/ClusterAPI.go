@@ -0,0 +1,14 @@
+package rancher
+
+import (
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// ClusterAPI returns the API used to discover clusters: the one set with --cluster-api, or
+// otherwise the one the server's capabilities list. It is auto when the server version is unknown.
+func ClusterAPI(cfg *config.Config) string {
+	if cfg.ClusterAPI == config.APINorman || cfg.ClusterAPI == config.APISteve {
+		return cfg.ClusterAPI
+	}
+	return ServerCapabilities(cfg).ClusterAPI
+}
@@ -0,0 +1,82 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// InventoryReport lists the selected clusters.
+type InventoryReport struct {
+	Clusters []Cluster `json:"clusters"`
+}
+
+// ClusterInventory lists the clusters selected by --cluster-type or --cluster-labels and
+// --kubernetes-version, the cluster named by --cluster-name, or every cluster. Unlike
+// SelectClusters it keeps clusters that are not active, so their state shows up in the report.
+func ClusterInventory(cfg *config.Config) (*InventoryReport, error) {
+	logger.Info("Building cluster inventory...")
+
+	matches := func(Cluster) bool { return true }
+	switch {
+	case cfg.SelectsClusters():
+		var err error
+		if matches, err = clusterMatcher(cfg); err != nil {
+			return nil, err
+		}
+	case cfg.ClusterName != "":
+		matches = func(cluster Cluster) bool { return cluster.Name == cfg.ClusterName }
+	}
+
+	clusters, err := ListClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list clusters: %v", err))
+		return nil, fmt.Errorf("failed to list clusters: %v", err)
+	}
+
+	report := &InventoryReport{Clusters: []Cluster{}}
+	for _, cluster := range clusters {
+		if matches(cluster) {
+			report.Clusters = append(report.Clusters, cluster)
+		}
+	}
+	sort.Slice(report.Clusters, func(i, j int) bool { return report.Clusters[i].Name < report.Clusters[j].Name })
+
+	logger.Info(fmt.Sprintf("Found %d cluster(s)", len(report.Clusters)))
+	return report, nil
+}
+
+// Write prints the report as a table or, with format json, as a JSON document.
+func (r *InventoryReport) Write(w io.Writer, format string) error {
+	if format == config.OutputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+
+	now := time.Now()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tID\tPROVIDER\tSTATE\tVERSION\tNODES\tCPU\tMEMORY\tAGE\tWORKSPACE\tPOOLS")
+	for _, c := range r.Clusters {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			c.Name, c.ID, c.Provider, c.State, c.KubernetesVersion, c.NodeCount, c.CPU, c.Memory, formatAge(now.Sub(c.Created)),
+			c.FleetWorkspace, formatMachinePools(c.MachinePools))
+	}
+	fmt.Fprintf(tw, "%d cluster(s)\n", len(r.Clusters))
+	return tw.Flush()
+}
+
+// formatMachinePools formats machine pools as name:quantity pairs, e.g. cp:1,workers:3.
+func formatMachinePools(pools []MachinePool) string {
+	pairs := make([]string, 0, len(pools))
+	for _, p := range pools {
+		pairs = append(pairs, fmt.Sprintf("%s:%d", p.Name, p.Quantity))
+	}
+	return strings.Join(pairs, ",")
+}
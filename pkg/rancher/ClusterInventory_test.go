@@ -0,0 +1,97 @@
+package rancher
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func addInventoryClusters(srv *ranchertest.Server) {
+	srv.AddCluster(ranchertest.Cluster{Name: "legacy", Provider: "rke2", KubernetesVersion: "v1.26.15+rke2r1", NodeCount: 3, CPU: "12", Memory: "48Gi",
+		MachinePools: []ranchertest.MachinePool{{Name: "all", Quantity: 3, EtcdRole: true, ControlPlaneRole: true, WorkerRole: true}}})
+	srv.AddCluster(ranchertest.Cluster{Name: "edge", Provider: "k3s", KubernetesVersion: "v1.27.6+k3s1", NodeCount: 1, CPU: "4", Memory: "8Gi"})
+	srv.AddCluster(ranchertest.Cluster{Name: "offline", Provider: "rke2", State: "unavailable", KubernetesVersion: "v1.25.9+rke2r1"})
+}
+
+func TestGetCluster(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	addInventoryClusters(srv)
+
+	cluster, err := GetCluster(cfg, "legacy")
+	require.NoError(t, err)
+	assert.Equal(t, "rke2", cluster.Provider)
+	assert.Equal(t, "v1.26.15+rke2r1", cluster.KubernetesVersion)
+	assert.Equal(t, 3, cluster.NodeCount)
+	assert.Equal(t, "12", cluster.CPU)
+	assert.Equal(t, "48Gi", cluster.Memory)
+	assert.True(t, cluster.Active())
+	assert.False(t, cluster.Created.IsZero())
+
+	_, err = GetCluster(cfg, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSelectClustersByKubernetesVersion(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	addInventoryClusters(srv)
+
+	cfg.ClusterName, cfg.KubernetesVersion = "", "<1.28"
+	selected, err := SelectClusters(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"legacy", "edge"}, clusterNames(selected))
+
+	cfg.ClusterType = "rke2"
+	selected, err = SelectClusters(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"legacy"}, clusterNames(selected))
+	// Each selection lists the clusters once instead of querying every cluster.
+	assert.Equal(t, 2, srv.CountRequests("GET", "/v1/management.cattle.io.clusters"))
+}
+
+func TestClusterInventory(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	addInventoryClusters(srv)
+
+	cfg.ClusterName = ""
+	report, err := ClusterInventory(cfg)
+	require.NoError(t, err)
+	var names []string
+	for _, c := range report.Clusters {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"downstream", "edge", "legacy", "offline"}, names)
+
+	// Clusters that are not active stay in the inventory.
+	cfg.KubernetesVersion = ">=1.25,<1.27"
+	report, err = ClusterInventory(cfg)
+	require.NoError(t, err)
+	require.Len(t, report.Clusters, 2)
+	assert.Equal(t, "legacy", report.Clusters[0].Name)
+	assert.Equal(t, "offline", report.Clusters[1].Name)
+
+	var text bytes.Buffer
+	require.NoError(t, report.Write(&text, config.OutputText))
+	assert.Contains(t, text.String(), "NAME")
+	assert.Regexp(t, `legacy\s+c-m-\S+\s+rke2\s+active\s+v1.26.15\+rke2r1\s+3\s+12\s+48Gi\s+\S+\s+fleet-default\s+all:3`, text.String())
+	assert.Contains(t, text.String(), "2 cluster(s)")
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out, config.OutputJSON))
+	var decoded InventoryReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, "unavailable", decoded.Clusters[1].State)
+}
+
+func clusterNames(clusters []ClusterRef) []string {
+	names := make([]string, 0, len(clusters))
+	for _, c := range clusters {
+		names = append(names, c.Name)
+	}
+	return names
+}
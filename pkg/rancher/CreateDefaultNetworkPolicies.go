@@ -0,0 +1,118 @@
+package rancher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/supporttools/rancher-projects/pkg/audit"
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// Names of the NetworkPolicies created by CreateDefaultNetworkPolicies.
+const (
+	DenyAllNetworkPolicy  = "default-deny-except-project"
+	AllowDNSNetworkPolicy = "allow-dns"
+)
+
+// CreateDefaultNetworkPolicies creates the default NetworkPolicies in a namespace of a project
+// through the cluster proxy: all ingress and egress is denied except to and from namespaces of
+// the same project, and DNS lookups are allowed. Policies that already exist are left alone.
+func CreateDefaultNetworkPolicies(cfg *config.Config, clusterID, namespace, projectID string) error {
+	logger.Info(fmt.Sprintf("Creating default network policies in namespace %s of cluster %s...", namespace, clusterID))
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/k8s/clusters/%s/v1/networking.k8s.io.networkpolicies", cfg.RancherServerURL, clusterID)
+	for _, policy := range defaultNetworkPolicies(namespace, projectID) {
+		if err := createNetworkPolicy(cfg, client, url, clusterID, policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createNetworkPolicy posts a single NetworkPolicy. A conflict means it already exists.
+func createNetworkPolicy(cfg *config.Config, client *http.Client, url, clusterID string, policy map[string]interface{}) (err error) {
+	metadata := policy["metadata"].(map[string]interface{})
+	object := fmt.Sprintf("networkpolicy/%s/%s", metadata["namespace"], metadata["name"])
+
+	payload, err := json.Marshal(policy)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to marshal %s: %v", object, err))
+		return fmt.Errorf("failed to marshal %s: %v", object, err)
+	}
+
+	req, err := NewRequest(cfg, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	entry := audit.Entry{Action: audit.ActionCreateNetworkPolicy, ClusterID: clusterID, Object: object, After: fmt.Sprint(metadata["name"])}
+	defer func() { auditChange(cfg, &entry, err) }()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		logger.Info(fmt.Sprintf("Created %s", object))
+		return nil
+	case http.StatusConflict:
+		logger.Info(fmt.Sprintf("%s already exists", object))
+		entry.Before, entry.Outcome = entry.After, audit.OutcomeUnchanged
+		return nil
+	default:
+		logger.Error(fmt.Sprintf("Failed to create %s, status code: %d", object, resp.StatusCode))
+		return fmt.Errorf("failed to create %s, status code: %d", object, resp.StatusCode)
+	}
+}
+
+// defaultNetworkPolicies returns the NetworkPolicy objects created in a namespace of a project.
+// Namespaces of a project are selected by the project label Rancher puts on them.
+func defaultNetworkPolicies(namespace, projectID string) []map[string]interface{} {
+	sameProject := []interface{}{map[string]interface{}{
+		"namespaceSelector": map[string]interface{}{
+			"matchLabels": map[string]string{ProjectIDAnnotation: projectID[strings.Index(projectID, ":")+1:]},
+		},
+	}}
+	policy := func(name string, spec map[string]interface{}) map[string]interface{} {
+		spec["podSelector"] = map[string]interface{}{}
+		return map[string]interface{}{
+			"type": "networking.k8s.io.networkpolicy",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+				"labels":    map[string]string{"app.kubernetes.io/managed-by": "rancher-projects"},
+			},
+			"spec": spec,
+		}
+	}
+
+	return []map[string]interface{}{
+		policy(DenyAllNetworkPolicy, map[string]interface{}{
+			"policyTypes": []string{"Ingress", "Egress"},
+			"ingress":     []interface{}{map[string]interface{}{"from": sameProject}},
+			"egress":      []interface{}{map[string]interface{}{"to": sameProject}},
+		}),
+		policy(AllowDNSNetworkPolicy, map[string]interface{}{
+			"policyTypes": []string{"Egress"},
+			"egress": []interface{}{map[string]interface{}{
+				"ports": []interface{}{
+					map[string]interface{}{"protocol": "UDP", "port": 53},
+					map[string]interface{}{"protocol": "TCP", "port": 53},
+				},
+			}},
+		}),
+	}
+}
@@ -0,0 +1,83 @@
+package rancher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/manifest"
+)
+
+func TestCreateDefaultNetworkPolicies(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	project := srv.AddProject(cluster.ID, "Team")
+	srv.AddNamespace(cluster.ID, "team", project.ID)
+
+	require.NoError(t, CreateDefaultNetworkPolicies(cfg, cluster.ID, "team", project.ID))
+	// A second run finds the policies in place.
+	require.NoError(t, CreateDefaultNetworkPolicies(cfg, cluster.ID, "team", project.ID))
+
+	policies := srv.NetworkPolicies(cluster.ID, "team")
+	require.Len(t, policies, 2)
+	assert.Equal(t, DenyAllNetworkPolicy, policies[0].Name)
+	assert.Equal(t, AllowDNSNetworkPolicy, policies[1].Name)
+	assert.Equal(t, "rancher-projects", policies[0].Labels["app.kubernetes.io/managed-by"])
+
+	projectPart := project.ID[strings.Index(project.ID, ":")+1:]
+	ingress := policies[0].Spec["ingress"].([]interface{})[0].(map[string]interface{})
+	selector := ingress["from"].([]interface{})[0].(map[string]interface{})["namespaceSelector"]
+	assert.Equal(t, map[string]interface{}{"matchLabels": map[string]interface{}{ProjectIDAnnotation: projectPart}}, selector)
+	assert.Equal(t, []interface{}{"Ingress", "Egress"}, policies[0].Spec["policyTypes"])
+	assert.Equal(t, []interface{}{"Egress"}, policies[1].Spec["policyTypes"])
+}
+
+func TestMainProjectCreatesNetworkPoliciesOnlyInNewNamespaces(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	project := srv.AddProject(cluster.ID, "Team")
+	srv.AddNamespace(cluster.ID, "existing", "")
+	cfg.ProjectName = "Team"
+	cfg.CreateNamespace = true
+	cfg.CreateNetworkPolicies = true
+
+	cfg.Namespace = "existing"
+	require.NoError(t, MainProject(cfg, cluster.ID))
+	assert.Empty(t, srv.NetworkPolicies(cluster.ID, "existing"))
+
+	cfg.Namespace = "fresh"
+	require.NoError(t, MainProject(cfg, cluster.ID))
+	assert.Len(t, srv.NetworkPolicies(cluster.ID, "fresh"), 2)
+	ns, ok := srv.Namespace(cluster.ID, "fresh")
+	require.True(t, ok)
+	assert.Equal(t, project.ID, ns.ProjectID())
+}
+
+func TestEnableProjectNetworkIsolation(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	project := srv.AddProject(cluster.ID, "Team")
+
+	require.NoError(t, EnableProjectNetworkIsolation(cfg, cluster.ID, project.ID))
+	require.NoError(t, EnableProjectNetworkIsolation(cfg, cluster.ID, project.ID))
+
+	policies := srv.ProjectNetworkPolicies(project.ID)
+	require.Len(t, policies, 1)
+	assert.Equal(t, "pnp-"+project.ID[strings.Index(project.ID, ":")+1:], policies[0].Name)
+	assert.Equal(t, 1, srv.CountRequests("POST", "/v3/projectnetworkpolicies"))
+}
+
+func TestReconcileIsolatesProjects(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	cfg.NetworkIsolation = true
+	cfg.CreateNetworkPolicies = true
+	desired := &manifest.Manifest{Projects: []manifest.Project{{Name: "Team", Namespaces: []string{"team"}}}}
+
+	summary, err := Reconcile(cfg, desired)
+	require.NoError(t, err)
+	assert.Empty(t, summary.Errors)
+
+	project, ok := srv.Project(cluster.ID, "Team")
+	require.True(t, ok)
+	assert.Len(t, srv.ProjectNetworkPolicies(project.ID), 1)
+	assert.Len(t, srv.NetworkPolicies(cluster.ID, "team"), 2)
+}
@@ -7,12 +7,25 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/supporttools/rancher-projects/pkg/audit"
 	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/metrics"
+	"github.com/supporttools/rancher-projects/pkg/notify"
 )
 
+// NamespaceSettleDelay is how long CreateNamespace waits after creating a namespace. Programs
+// embedding the package, and tests against pkg/ranchertest, may lower it.
+var NamespaceSettleDelay = 5 * time.Second
+
 // CreateNamespace attempts to create a namespace within a specified cluster.
-// It waits for 5 seconds if the namespace is successfully created to allow it to settle.
+// It waits for NamespaceSettleDelay if the namespace is successfully created to allow it to settle.
 func CreateNamespace(cfg *config.Config, clusterID, namespace string) error {
+	_, err := createNamespace(cfg, clusterID, namespace)
+	return err
+}
+
+// createNamespace is CreateNamespace, also reporting whether the namespace was created or already existed.
+func createNamespace(cfg *config.Config, clusterID, namespace string) (created bool, err error) {
 	logger.Info(fmt.Sprintf("Checking if namespace %s exists in cluster %s...", namespace, clusterID))
 
 	url := fmt.Sprintf("%s/k8s/clusters/%s/v1/namespaces", cfg.RancherServerURL, clusterID)
@@ -27,40 +40,45 @@ func CreateNamespace(cfg *config.Config, clusterID, namespace string) error {
 	reqBodyBytes, err := json.Marshal(namespaceData)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to marshal request body for namespace %s: %v", namespace, err))
-		return fmt.Errorf("failed to marshal request body: %v", err)
+		return false, fmt.Errorf("failed to marshal request body: %v", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(reqBodyBytes))
+	req, err := NewRequest(cfg, "POST", url, bytes.NewReader(reqBodyBytes))
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create HTTP request for namespace %s: %v", namespace, err))
-		return fmt.Errorf("failed to create HTTP request for namespace %s: %v", namespace, err)
+		return false, fmt.Errorf("failed to create HTTP request for namespace %s: %v", namespace, err)
 	}
-	req.SetBasicAuth(cfg.RancherAccessKey, cfg.RancherSecretKey)
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{
-		Timeout: time.Second * 10, // 10 seconds timeout
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return false, err
 	}
 
+	entry := audit.Entry{Action: audit.ActionCreateNamespace, ClusterID: clusterID, Object: "namespace/" + namespace, After: namespace}
+	defer func() { auditChange(cfg, &entry, err) }()
+
 	logger.Info(fmt.Sprintf("Sending request to create namespace %s...", namespace))
 	resp, err := client.Do(req)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to check if namespace %s exists: %v", namespace, err))
-		return fmt.Errorf("failed to check if namespace %s exists: %v", namespace, err)
+		return false, fmt.Errorf("failed to check if namespace %s exists: %v", namespace, err)
 	}
 	defer resp.Body.Close()
 
 	switch resp.StatusCode {
 	case http.StatusCreated:
 		logger.Info(fmt.Sprintf("Successfully created namespace %s", namespace))
-		logger.Info("Sleeping for 5 seconds to allow namespace to settle...")
-		time.Sleep(5 * time.Second)
-		return nil
+		metrics.NamespacesCreated.WithLabelValues(clusterID).Inc()
+		Notify(cfg, notify.Event{Type: notify.NamespaceCreated, ClusterID: clusterID, Namespace: namespace})
+		logger.Info(fmt.Sprintf("Sleeping for %s to allow namespace to settle...", NamespaceSettleDelay))
+		time.Sleep(NamespaceSettleDelay)
+		return true, nil
 	case http.StatusConflict:
 		logger.Warn(fmt.Sprintf("Namespace %s already exists", namespace))
-		return nil
+		entry.Before, entry.Outcome = namespace, audit.OutcomeUnchanged
+		return false, nil
 	default:
 		logger.Error(fmt.Sprintf("Failed to create namespace %s. Status code: %d", namespace, resp.StatusCode))
-		return fmt.Errorf("failed to create namespace %s. Status code: %d", namespace, resp.StatusCode)
+		return false, fmt.Errorf("failed to create namespace %s. Status code: %d", namespace, resp.StatusCode)
 	}
 }
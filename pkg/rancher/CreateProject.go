@@ -5,28 +5,32 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
+	"github.com/supporttools/rancher-projects/pkg/audit"
 	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/metrics"
+	"github.com/supporttools/rancher-projects/pkg/notify"
 )
 
 // CreateProject checks for the existence of a project by name within a cluster and creates it if not found.
-func CreateProject(cfg *config.Config, clusterID, projectName string) error {
+func CreateProject(cfg *config.Config, clusterID, projectName string) (err error) {
+	if ProjectAPI(cfg) == config.APISteve {
+		return CreateSteveProject(cfg, clusterID, projectName)
+	}
 	logger.Info(fmt.Sprintf("Starting CreateProject for project %s in cluster %s", projectName, clusterID))
 
-	url := fmt.Sprintf("%s/v3/projects?clusterId=%s&name=%s", cfg.RancherServerURL, clusterID, projectName)
+	url := projectQuery(cfg, clusterID, projectName)
 	logger.Debug(fmt.Sprintf("Generated request URL for project check: %s", url))
 
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create GET request for project %s: %v", projectName, err))
 		return fmt.Errorf("failed to check if project %s exists: %v", projectName, err)
 	}
-	req.SetBasicAuth(cfg.RancherAccessKey, cfg.RancherSecretKey)
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{
-		Timeout: time.Second * 10, // 10 seconds timeout
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
 	}
 
 	logger.Info(fmt.Sprintf("Sending GET request to check if project %s exists...", projectName))
@@ -37,52 +41,73 @@ func CreateProject(cfg *config.Config, clusterID, projectName string) error {
 	}
 	defer resp.Body.Close()
 
-	switch resp.StatusCode {
-	case http.StatusOK:
-		logger.Info(fmt.Sprintf("Project %s already exists", projectName))
-		return nil
-	case http.StatusNotFound:
-		logger.Info(fmt.Sprintf("Project %s not found, proceeding to create it...", projectName))
-
-		createProjectURL := fmt.Sprintf("%s/v3/projects", cfg.RancherServerURL)
-		projectData := map[string]string{
-			"type":      "project",
-			"name":      projectName,
-			"clusterId": clusterID,
-		}
-
-		reqBodyBytes, err := json.Marshal(projectData)
-		if err != nil {
-			logger.Error(fmt.Sprintf("Failed to marshal project data for %s: %v", projectName, err))
-			return fmt.Errorf("failed to marshal project data for %s: %v", projectName, err)
-		}
-		logger.Debug(fmt.Sprintf("Generated request body for project creation: %s", string(reqBodyBytes)))
-
-		req, err = http.NewRequest("POST", createProjectURL, bytes.NewReader(reqBodyBytes))
-		if err != nil {
-			logger.Error(fmt.Sprintf("Failed to create POST request for new project %s: %v", projectName, err))
-			return fmt.Errorf("failed to create request for new project %s: %v", projectName, err)
-		}
-		req.SetBasicAuth(cfg.RancherAccessKey, cfg.RancherSecretKey)
-		req.Header.Set("Content-Type", "application/json")
-
-		logger.Info(fmt.Sprintf("Sending POST request to create project %s...", projectName))
-		resp, err = client.Do(req)
-		if err != nil {
-			logger.Error(fmt.Sprintf("Failed to execute POST request for project %s: %v", projectName, err))
-			return fmt.Errorf("failed to create project %s: %v", projectName, err)
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusCreated {
-			logger.Error(fmt.Sprintf("Failed to create project %s. Status code: %d", projectName, resp.StatusCode))
-			return fmt.Errorf("failed to create project %s. Status code: %d", projectName, resp.StatusCode)
-		}
-
-		logger.Info(fmt.Sprintf("Successfully created project %s", projectName))
-		return nil
-	default:
+	if resp.StatusCode != http.StatusOK {
 		logger.Error(fmt.Sprintf("Unexpected status code %d while checking project %s", resp.StatusCode, projectName))
 		return fmt.Errorf("unexpected status code %d while checking project %s", resp.StatusCode, projectName)
 	}
+
+	// The collection endpoint returns 200 with an empty data list when nothing matches.
+	var existing struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&existing); err != nil {
+		logger.Error(fmt.Sprintf("Failed to decode JSON response for project %s: %v", projectName, err))
+		return fmt.Errorf("failed to decode JSON response for project %s: %v", projectName, err)
+	}
+	if len(existing.Data) > 0 {
+		logger.Info(fmt.Sprintf("Project %s already exists", projectName))
+		return nil
+	}
+
+	logger.Info(fmt.Sprintf("Project %s not found, proceeding to create it...", projectName))
+
+	createProjectURL := fmt.Sprintf("%s/v3/projects", cfg.RancherServerURL)
+	projectData := map[string]string{
+		"type":      "project",
+		"name":      projectName,
+		"clusterId": clusterID,
+	}
+
+	reqBodyBytes, err := json.Marshal(projectData)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to marshal project data for %s: %v", projectName, err))
+		return fmt.Errorf("failed to marshal project data for %s: %v", projectName, err)
+	}
+	logger.Debug(fmt.Sprintf("Generated request body for project creation: %s", string(reqBodyBytes)))
+
+	req, err = NewRequest(cfg, "POST", createProjectURL, bytes.NewReader(reqBodyBytes))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create POST request for new project %s: %v", projectName, err))
+		return fmt.Errorf("failed to create request for new project %s: %v", projectName, err)
+	}
+
+	entry := audit.Entry{Action: audit.ActionCreateProject, ClusterID: clusterID, Object: "project/" + projectName}
+	defer func() { auditChange(cfg, &entry, err) }()
+
+	logger.Info(fmt.Sprintf("Sending POST request to create project %s...", projectName))
+	resp, err = client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to execute POST request for project %s: %v", projectName, err))
+		return fmt.Errorf("failed to create project %s: %v", projectName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		logger.Error(fmt.Sprintf("Failed to create project %s. Status code: %d", projectName, resp.StatusCode))
+		return fmt.Errorf("failed to create project %s. Status code: %d", projectName, resp.StatusCode)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err == nil {
+		entry.After = created.ID
+	}
+
+	logger.Info(fmt.Sprintf("Successfully created project %s", projectName))
+	metrics.ProjectsCreated.WithLabelValues(clusterID).Inc()
+	Notify(cfg, notify.Event{Type: notify.ProjectCreated, ClusterID: clusterID, Project: projectName})
+	return nil
 }
@@ -0,0 +1,80 @@
+package rancher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/supporttools/rancher-projects/pkg/audit"
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/metrics"
+	"github.com/supporttools/rancher-projects/pkg/notify"
+)
+
+// CreateSteveProject creates a management.cattle.io Project with the given display name in a
+// cluster through the Steve API unless the cluster already has one.
+func CreateSteveProject(cfg *config.Config, clusterID, projectName string) (err error) {
+	logger.Info(fmt.Sprintf("Starting CreateProject for project %s in cluster %s", projectName, clusterID))
+
+	existing, err := findSteveProject(cfg, clusterID, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to check if project %s exists: %v", projectName, err)
+	}
+	if existing != nil {
+		logger.Info(fmt.Sprintf("Project %s already exists", projectName))
+		return nil
+	}
+
+	logger.Info(fmt.Sprintf("Project %s not found, proceeding to create it...", projectName))
+
+	projectData := map[string]interface{}{
+		"type":     "management.cattle.io.project",
+		"metadata": map[string]string{"generateName": "p-", "namespace": clusterID},
+		"spec":     map[string]string{"displayName": projectName, "clusterName": clusterID},
+	}
+	reqBodyBytes, err := json.Marshal(projectData)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to marshal project data for %s: %v", projectName, err))
+		return fmt.Errorf("failed to marshal project data for %s: %v", projectName, err)
+	}
+	logger.Debug(fmt.Sprintf("Generated request body for project creation: %s", string(reqBodyBytes)))
+
+	url := fmt.Sprintf("%s/v1/management.cattle.io.projects", cfg.RancherServerURL)
+	req, err := NewRequest(cfg, "POST", url, bytes.NewReader(reqBodyBytes))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create POST request for new project %s: %v", projectName, err))
+		return fmt.Errorf("failed to create request for new project %s: %v", projectName, err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	entry := audit.Entry{Action: audit.ActionCreateProject, ClusterID: clusterID, Object: "project/" + projectName}
+	defer func() { auditChange(cfg, &entry, err) }()
+
+	logger.Info(fmt.Sprintf("Sending POST request to create project %s...", projectName))
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to execute POST request for project %s: %v", projectName, err))
+		return fmt.Errorf("failed to create project %s: %v", projectName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		logger.Error(fmt.Sprintf("Failed to create project %s. Status code: %d", projectName, resp.StatusCode))
+		return fmt.Errorf("failed to create project %s. Status code: %d", projectName, resp.StatusCode)
+	}
+
+	var created steveProject
+	if err := json.NewDecoder(resp.Body).Decode(&created); err == nil {
+		entry.After = created.project().Id
+	}
+
+	logger.Info(fmt.Sprintf("Successfully created project %s", projectName))
+	metrics.ProjectsCreated.WithLabelValues(clusterID).Inc()
+	Notify(cfg, notify.Event{Type: notify.ProjectCreated, ClusterID: clusterID, Project: projectName})
+	return nil
+}
@@ -0,0 +1,29 @@
+package rancher
+
+import (
+	"fmt"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/manifest"
+)
+
+// DesiredState returns the projects and namespaces that should exist in each selected cluster:
+// the manifest given with --manifest, or the single project and namespace from --project-name,
+// --pod-security and --namespace.
+func DesiredState(cfg *config.Config) (*manifest.Manifest, error) {
+	if cfg.ManifestFile != "" {
+		logger.Info(fmt.Sprintf("Loading manifest %s...", cfg.ManifestFile))
+		m, err := manifest.Load(cfg.ManifestFile)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to load manifest: %v", err))
+			return nil, err
+		}
+		return m, nil
+	}
+
+	project := manifest.Project{Name: cfg.ProjectName, PodSecurity: cfg.PodSecurity}
+	if cfg.Namespace != "" {
+		project.Namespaces = []string{cfg.Namespace}
+	}
+	return &manifest.Manifest{Projects: []manifest.Project{project}}, nil
+}
@@ -0,0 +1,195 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/manifest"
+	"github.com/supporttools/rancher-projects/pkg/metrics"
+)
+
+// Kinds of drift reported by DetectDrift.
+const (
+	DriftMissingProject      = "MissingProject"
+	DriftMissingNamespace    = "MissingNamespace"
+	DriftWrongProject        = "WrongProject"
+	DriftUnexpectedNamespace = "UnexpectedNamespace"
+)
+
+// DriftFinding is a single difference between the desired and the actual state of a cluster.
+type DriftFinding struct {
+	Kind      string `json:"kind"`
+	Project   string `json:"project"`
+	Namespace string `json:"namespace,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// ClusterDrift holds the findings for one cluster. Error is set when the cluster could not be inspected.
+type ClusterDrift struct {
+	Cluster   string         `json:"cluster"`
+	ClusterID string         `json:"clusterId"`
+	Findings  []DriftFinding `json:"findings"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// DriftReport is the result of comparing the desired state with every selected cluster.
+type DriftReport struct {
+	Drift    bool           `json:"drift"`
+	Clusters []ClusterDrift `json:"clusters"`
+}
+
+// HasErrors reports whether any cluster could not be inspected.
+func (r *DriftReport) HasErrors() bool {
+	for _, c := range r.Clusters {
+		if c.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectDrift compares the desired projects and namespaces with each selected cluster. Clusters
+// that cannot be inspected are recorded in the report instead of aborting the run.
+func DetectDrift(cfg *config.Config, desired *manifest.Manifest) (*DriftReport, error) {
+	logger.Info("Detecting drift...")
+
+	clusters, err := SelectClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to select clusters: %v", err))
+		return nil, fmt.Errorf("failed to select clusters: %v", err)
+	}
+
+	report := &DriftReport{Clusters: []ClusterDrift{}}
+	for _, cluster := range clusters {
+		result := ClusterDrift{Cluster: cluster.Name, ClusterID: cluster.ID, Findings: []DriftFinding{}}
+
+		projects, err := ListProjects(cfg, cluster.ID)
+		if err == nil {
+			var namespaces []Namespace
+			namespaces, err = ListNamespaces(cfg, cluster.ID)
+			if err == nil {
+				result.Findings = CompareCluster(desired, projects, namespaces)
+				recordDrift(cluster.ID, result.Findings)
+			}
+		}
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to inspect cluster %s: %v", cluster.Name, err))
+			result.Error = err.Error()
+		}
+
+		logger.Info(fmt.Sprintf("Cluster %s: %d drift finding(s)", cluster.Name, len(result.Findings)))
+		report.Drift = report.Drift || len(result.Findings) > 0
+		report.Clusters = append(report.Clusters, result)
+	}
+	return report, nil
+}
+
+// CompareCluster returns the differences between the desired state and the projects and namespaces
+// of a cluster. Only namespaces assigned to a desired project can be unexpected, so namespaces
+// managed outside the manifest are left alone.
+func CompareCluster(desired *manifest.Manifest, projects []Project, namespaces []Namespace) []DriftFinding {
+	findings := []DriftFinding{}
+
+	projectIDs := map[string]string{}
+	projectNames := map[string]string{}
+	for _, p := range projects {
+		projectIDs[p.Name] = p.Id
+		projectNames[p.Id] = p.Name
+	}
+	actual := map[string]Namespace{}
+	for _, ns := range namespaces {
+		actual[ns.Name] = ns
+	}
+	wanted := map[string]string{}
+
+	for _, p := range desired.Projects {
+		projectID, exists := projectIDs[p.Name]
+		if !exists {
+			findings = append(findings, DriftFinding{Kind: DriftMissingProject, Project: p.Name})
+		}
+
+		for _, name := range p.Namespaces {
+			wanted[name] = p.Name
+			ns, ok := actual[name]
+			switch {
+			case !ok:
+				findings = append(findings, DriftFinding{Kind: DriftMissingNamespace, Project: p.Name, Namespace: name})
+			case !exists || ns.ProjectID() != projectID:
+				findings = append(findings, DriftFinding{
+					Kind:      DriftWrongProject,
+					Project:   p.Name,
+					Namespace: name,
+					Detail:    fmt.Sprintf("assigned to %s", describeProject(ns.ProjectID(), projectNames)),
+				})
+			}
+		}
+	}
+
+	for _, ns := range namespaces {
+		projectName, managed := projectNames[ns.ProjectID()]
+		if _, desiredProject := desired.Project(projectName); !managed || !desiredProject {
+			continue
+		}
+		if _, ok := wanted[ns.Name]; !ok {
+			findings = append(findings, DriftFinding{Kind: DriftUnexpectedNamespace, Project: projectName, Namespace: ns.Name})
+		}
+	}
+	return findings
+}
+
+// recordDrift sets the DriftFindings gauge of a cluster, including zero for kinds without findings.
+func recordDrift(clusterID string, findings []DriftFinding) {
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[f.Kind]++
+	}
+	for _, kind := range []string{DriftMissingProject, DriftMissingNamespace, DriftWrongProject, DriftUnexpectedNamespace} {
+		metrics.DriftFindings.WithLabelValues(clusterID, kind).Set(float64(counts[kind]))
+	}
+}
+
+func describeProject(projectID string, projectNames map[string]string) string {
+	if projectID == "" {
+		return "no project"
+	}
+	if name, ok := projectNames[projectID]; ok {
+		return fmt.Sprintf("project %s", name)
+	}
+	return fmt.Sprintf("unknown project %s", projectID)
+}
+
+// Write prints the report as a table or, with format json, as a JSON document.
+func (r *DriftReport) Write(w io.Writer, format string) error {
+	if format == config.OutputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	drifted := 0
+	for _, c := range r.Clusters {
+		switch {
+		case c.Error != "":
+			fmt.Fprintf(tw, "Cluster %s (%s): error: %s\n", c.Cluster, c.ClusterID, c.Error)
+			continue
+		case len(c.Findings) == 0:
+			fmt.Fprintf(tw, "Cluster %s (%s): no drift\n", c.Cluster, c.ClusterID)
+			continue
+		}
+		drifted++
+		fmt.Fprintf(tw, "Cluster %s (%s): %d finding(s)\n", c.Cluster, c.ClusterID, len(c.Findings))
+		for _, f := range c.Findings {
+			object := f.Project
+			if f.Namespace != "" {
+				object += "/" + f.Namespace
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", f.Kind, object, f.Detail)
+		}
+	}
+	fmt.Fprintf(tw, "Drift detected in %d of %d cluster(s)\n", drifted, len(r.Clusters))
+	return tw.Flush()
+}
@@ -0,0 +1,81 @@
+package rancher
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/manifest"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestDetectDrift(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	cfg.ClusterName = ""
+	cfg.ClusterLabels = "env=prod"
+
+	prod := srv.AddCluster(ranchertest.Cluster{Name: "prod", Labels: map[string]string{"env": "prod"}})
+	clean := srv.AddCluster(ranchertest.Cluster{Name: "prod-clean", Labels: map[string]string{"env": "prod"}})
+	srv.AddCluster(ranchertest.Cluster{Name: "prod-down", State: "unavailable", Labels: map[string]string{"env": "prod"}})
+
+	services := srv.AddProject(prod.ID, "ClusterServices")
+	other := srv.AddProject(prod.ID, "Default")
+	srv.AddNamespace(prod.ID, "monitoring", other.ID)
+	srv.AddNamespace(prod.ID, "scratch", services.ID)
+	srv.AddNamespace(prod.ID, "kube-system", "")
+
+	cleanServices := srv.AddProject(clean.ID, "ClusterServices")
+	srv.AddProject(clean.ID, "TeamA")
+	srv.AddNamespace(clean.ID, "monitoring", cleanServices.ID)
+	srv.AddNamespace(clean.ID, "logging", cleanServices.ID)
+
+	desired := &manifest.Manifest{Projects: []manifest.Project{
+		{Name: "ClusterServices", Namespaces: []string{"monitoring", "logging"}},
+		{Name: "TeamA"},
+	}}
+
+	report, err := DetectDrift(cfg, desired)
+	require.NoError(t, err)
+	assert.True(t, report.Drift)
+	assert.False(t, report.HasErrors())
+	require.Len(t, report.Clusters, 2)
+
+	assert.Equal(t, "prod", report.Clusters[0].Cluster)
+	assert.ElementsMatch(t, []DriftFinding{
+		{Kind: DriftWrongProject, Project: "ClusterServices", Namespace: "monitoring", Detail: "assigned to project Default"},
+		{Kind: DriftMissingNamespace, Project: "ClusterServices", Namespace: "logging"},
+		{Kind: DriftMissingProject, Project: "TeamA"},
+		{Kind: DriftUnexpectedNamespace, Project: "ClusterServices", Namespace: "scratch"},
+	}, report.Clusters[0].Findings)
+
+	assert.Equal(t, "prod-clean", report.Clusters[1].Cluster)
+	assert.Empty(t, report.Clusters[1].Findings)
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out, config.OutputText))
+	assert.Contains(t, out.String(), "Cluster prod-clean ("+clean.ID+"): no drift")
+	assert.Contains(t, out.String(), "Drift detected in 1 of 2 cluster(s)")
+
+	out.Reset()
+	require.NoError(t, report.Write(&out, config.OutputJSON))
+	assert.Contains(t, out.String(), `"kind": "MissingProject"`)
+}
+
+func TestDetectDriftRecordsClusterErrors(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	srv.Fail("GET", "/k8s/clusters/"+cluster.ID, 503)
+
+	report, err := DetectDrift(cfg, &manifest.Manifest{Projects: []manifest.Project{{Name: "ClusterServices"}}})
+	require.NoError(t, err)
+	assert.True(t, report.HasErrors())
+	assert.Contains(t, report.Clusters[0].Error, "status code: 503")
+}
+
+func TestDesiredStateFromFlags(t *testing.T) {
+	desired, err := DesiredState(&config.Config{ProjectName: "ClusterServices", Namespace: "monitoring"})
+	require.NoError(t, err)
+	assert.Equal(t, []manifest.Project{{Name: "ClusterServices", Namespaces: []string{"monitoring"}}}, desired.Projects)
+}
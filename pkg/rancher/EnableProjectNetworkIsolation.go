@@ -0,0 +1,129 @@
+package rancher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/supporttools/rancher-projects/pkg/audit"
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// EnableProjectNetworkIsolation makes sure a project has a project network policy, which Rancher
+// uses to isolate the project's namespaces from other projects. Rancher only enforces it when
+// project network isolation is enabled for the cluster; a warning is logged when it is not.
+func EnableProjectNetworkIsolation(cfg *config.Config, clusterID, projectID string) (err error) {
+	logger.Info(fmt.Sprintf("Enabling network isolation for project %s in cluster %s...", projectID, clusterID))
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	enabled, err := clusterNetworkPolicyEnabled(cfg, client, clusterID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		logger.Warn(fmt.Sprintf("Project network isolation is disabled for cluster %s; the policy for project %s is not enforced until it is enabled", clusterID, projectID))
+	}
+
+	url := fmt.Sprintf("%s/v3/projectnetworkpolicies?projectId=%s", cfg.RancherServerURL, projectID)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Failed to list network policies of project %s, status code: %d", projectID, resp.StatusCode))
+		return fmt.Errorf("failed to list network policies of project %s, status code: %d", projectID, resp.StatusCode)
+	}
+
+	var existing struct {
+		Data []ProjectNetworkPolicy `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&existing); err != nil {
+		logger.Error(fmt.Sprintf("Failed to decode JSON response: %v", err))
+		return fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+	if len(existing.Data) > 0 {
+		logger.Info(fmt.Sprintf("Project %s already has network policy %s", projectID, existing.Data[0].Name))
+		return nil
+	}
+
+	policy := ProjectNetworkPolicy{
+		ProjectID:   projectID,
+		Name:        "pnp-" + projectID[strings.Index(projectID, ":")+1:],
+		Description: "Isolates the namespaces of the project from other projects",
+	}
+	payload, err := json.Marshal(policy)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to marshal project network policy: %v", err))
+		return fmt.Errorf("failed to marshal project network policy: %v", err)
+	}
+
+	req, err = NewRequest(cfg, "POST", fmt.Sprintf("%s/v3/projectnetworkpolicies", cfg.RancherServerURL), bytes.NewReader(payload))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	entry := audit.Entry{Action: audit.ActionIsolateProject, ClusterID: clusterID, Object: "project/" + projectID, After: policy.Name}
+	defer func() { auditChange(cfg, &entry, err) }()
+
+	resp, err = client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		logger.Error(fmt.Sprintf("Failed to create network policy for project %s, status code: %d", projectID, resp.StatusCode))
+		return fmt.Errorf("failed to create network policy for project %s, status code: %d", projectID, resp.StatusCode)
+	}
+
+	logger.Info(fmt.Sprintf("Enabled network isolation for project %s", projectID))
+	return nil
+}
+
+// clusterNetworkPolicyEnabled reports whether project network isolation is enabled for a cluster.
+func clusterNetworkPolicyEnabled(cfg *config.Config, client *http.Client, clusterID string) (bool, error) {
+	url := fmt.Sprintf("%s/v3/clusters/%s", cfg.RancherServerURL, clusterID)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return false, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return false, fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Failed to get cluster %s, status code: %d", clusterID, resp.StatusCode))
+		return false, fmt.Errorf("failed to get cluster %s, status code: %d", clusterID, resp.StatusCode)
+	}
+
+	var cluster struct {
+		EnableNetworkPolicy bool `json:"enableNetworkPolicy"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&cluster); err != nil {
+		logger.Error(fmt.Sprintf("Failed to decode JSON response: %v", err))
+		return false, fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+	return cluster.EnableNetworkPolicy, nil
+}
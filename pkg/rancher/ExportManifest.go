@@ -0,0 +1,97 @@
+package rancher
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/manifest"
+	"github.com/supporttools/rancher-projects/pkg/podsecurity"
+)
+
+// ExportManifest reads the projects of every selected cluster and the namespaces assigned to
+// them and returns them as a manifest that apply, drift and daemon accept. Projects with the
+// same name in several clusters are merged. A namespace assigned to different projects in
+// different clusters is kept in the project it was first found in and reported as a warning.
+// Namespaces not assigned to any project are left out.
+func ExportManifest(cfg *config.Config) (*manifest.Manifest, error) {
+	logger.Info("Exporting projects and namespaces...")
+
+	clusters, err := SelectClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to select clusters: %v", err))
+		return nil, fmt.Errorf("failed to select clusters: %v", err)
+	}
+
+	exported := &manifest.Manifest{}
+	for _, cluster := range clusters {
+		projects, err := ListProjects(cfg, cluster.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export cluster %s: %v", cluster.Name, err)
+		}
+		namespaces, err := ListNamespaces(cfg, cluster.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export cluster %s: %v", cluster.Name, err)
+		}
+
+		for _, conflict := range MergeCluster(exported, projects, namespaces) {
+			logger.Warn(fmt.Sprintf("Cluster %s: %s", cluster.Name, conflict))
+		}
+		logger.Info(fmt.Sprintf("Exported %d project(s) from cluster %s", len(projects), cluster.Name))
+	}
+	return exported, nil
+}
+
+// MergeCluster adds the projects of a cluster and the namespaces assigned to them to m, keeping
+// projects and namespaces sorted by name. It returns a description of every namespace that m
+// already lists under a different project; those namespaces are not moved.
+func MergeCluster(m *manifest.Manifest, projects []Project, namespaces []Namespace) []string {
+	index := map[string]int{}
+	owner := map[string]string{}
+	for i, p := range m.Projects {
+		index[p.Name] = i
+		for _, ns := range p.Namespaces {
+			owner[ns] = p.Name
+		}
+	}
+
+	projectNames := map[string]string{}
+	for _, p := range projects {
+		projectNames[p.Id] = p.Name
+		i, ok := index[p.Name]
+		if !ok {
+			m.Projects = append(m.Projects, manifest.Project{Name: p.Name})
+			i = len(m.Projects) - 1
+			index[p.Name] = i
+		}
+		if m.Projects[i].Description == "" {
+			m.Projects[i].Description = p.Description
+		}
+		if m.Projects[i].PodSecurity == "" {
+			m.Projects[i].PodSecurity = p.Annotations[podsecurity.Annotation]
+		}
+	}
+
+	var conflicts []string
+	for _, ns := range namespaces {
+		project, ok := projectNames[ns.ProjectID()]
+		if !ok {
+			continue
+		}
+		if existing, ok := owner[ns.Name]; ok {
+			if existing != project {
+				conflicts = append(conflicts, fmt.Sprintf("namespace %s is assigned to project %s here and to %s elsewhere; keeping %s", ns.Name, project, existing, existing))
+			}
+			continue
+		}
+		owner[ns.Name] = project
+		i := index[project]
+		m.Projects[i].Namespaces = append(m.Projects[i].Namespaces, ns.Name)
+	}
+
+	sort.Slice(m.Projects, func(i, j int) bool { return m.Projects[i].Name < m.Projects[j].Name })
+	for i := range m.Projects {
+		sort.Strings(m.Projects[i].Namespaces)
+	}
+	return conflicts
+}
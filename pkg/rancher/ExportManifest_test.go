@@ -0,0 +1,63 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/manifest"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestExportManifest(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	cfg.ClusterName = ""
+	cfg.ClusterLabels = "env=prod"
+
+	a := srv.AddCluster(ranchertest.Cluster{Name: "prod-a", Labels: map[string]string{"env": "prod"}})
+	b := srv.AddCluster(ranchertest.Cluster{Name: "prod-b", Labels: map[string]string{"env": "prod"}})
+
+	servicesA := srv.AddProject(a.ID, "ClusterServices")
+	srv.UpdateProject(a.ID, "ClusterServices", func(p *ranchertest.Project) { p.Description = "Shared services" })
+	teamA := srv.AddProject(a.ID, "TeamA")
+	srv.AddNamespace(a.ID, "monitoring", servicesA.ID)
+	srv.AddNamespace(a.ID, "team-a", teamA.ID)
+	srv.AddNamespace(a.ID, "scratch", "")
+
+	servicesB := srv.AddProject(b.ID, "ClusterServices")
+	teamB := srv.AddProject(b.ID, "TeamB")
+	srv.AddNamespace(b.ID, "logging", servicesB.ID)
+	srv.AddNamespace(b.ID, "monitoring", teamB.ID) // conflicts with prod-a, which wins
+	srv.AddNamespace(b.ID, "team-b", teamB.ID)
+
+	exported, err := ExportManifest(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []manifest.Project{
+		{Name: "ClusterServices", Description: "Shared services", Namespaces: []string{"logging", "monitoring"}},
+		{Name: "TeamA", Namespaces: []string{"team-a"}},
+		{Name: "TeamB", Namespaces: []string{"team-b"}},
+	}, exported.Projects)
+	require.NoError(t, exported.Validate())
+
+	// A cluster matches its own export.
+	cfg.ClusterLabels = ""
+	cfg.ClusterName = a.Name
+	exported, err = ExportManifest(cfg)
+	require.NoError(t, err)
+	report, err := DetectDrift(cfg, exported)
+	require.NoError(t, err)
+	assert.False(t, report.Drift, report.Clusters)
+}
+
+func TestMergeClusterReportsConflicts(t *testing.T) {
+	m := &manifest.Manifest{Projects: []manifest.Project{{Name: "A", Namespaces: []string{"shared"}}}}
+	conflicts := MergeCluster(m,
+		[]Project{{Id: "c-1:p-b", Name: "B"}},
+		[]Namespace{{Name: "shared", Annotations: map[string]string{ProjectIDAnnotation: "c-1:p-b"}}},
+	)
+	require.Len(t, conflicts, 1)
+	assert.Contains(t, conflicts[0], "namespace shared is assigned to project B here and to A elsewhere")
+	assert.Equal(t, []string{"shared"}, m.Projects[0].Namespaces)
+	assert.Empty(t, m.Projects[1].Namespaces)
+}
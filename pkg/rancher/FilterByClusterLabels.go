@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/supporttools/rancher-projects/pkg/config"
 )
@@ -19,20 +18,19 @@ func FilterByClusterLabels(clusterName string, keyPairs []string, cfg *config.Co
 	logger.Debug(fmt.Sprintf("Labels to match: %s", strings.Join(keyPairs, ", ")))
 
 	// Prepare the request to the Rancher API.
-	url := fmt.Sprintf("%s/v3/clusters?name=%s", cfg.RancherServerURL, clusterName)
+	url := clusterQuery(cfg, clusterName)
 	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
 
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create request: %v", err))
 		return false, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.SetBasicAuth(cfg.RancherAccessKey, cfg.RancherSecretKey)
-	req.Header.Set("Content-Type", "application/json")
 
 	// Execute the HTTP request.
-	client := &http.Client{
-		Timeout: time.Second * 10, // 10 seconds timeout
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return false, err
 	}
 
 	logger.Info("Sending GET request to fetch cluster labels...")
@@ -56,14 +54,16 @@ func FilterByClusterLabels(clusterName string, keyPairs []string, cfg *config.Co
 	}
 	logger.Debug(fmt.Sprintf("Received response body: %s", string(body)))
 
-	var clusters []map[string]interface{}
+	var clusters struct {
+		Data []map[string]interface{} `json:"data"`
+	}
 	if err := json.Unmarshal(body, &clusters); err != nil {
 		logger.Error(fmt.Sprintf("Failed to parse clusters by label: %v", err))
 		return false, fmt.Errorf("failed to parse clusters by label: %w", err)
 	}
 
 	// Iterate over the clusters and their labels to find a match.
-	for _, cluster := range clusters {
+	for _, cluster := range clusters.Data {
 		labels, ok := cluster["labels"].(map[string]interface{})
 		if !ok {
 			logger.Warn(fmt.Sprintf("Skipping cluster %v due to missing or invalid labels format", cluster))
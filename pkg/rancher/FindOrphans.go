@@ -0,0 +1,154 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// OrphanNamespace is a namespace that is not assigned to any project.
+type OrphanNamespace struct {
+	Name    string            `json:"name"`
+	Created time.Time         `json:"creationTimestamp"`
+	Age     string            `json:"age"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// ClusterOrphans holds the orphaned namespaces of one cluster. Error is set when the cluster could not be inspected.
+type ClusterOrphans struct {
+	Cluster    string            `json:"cluster"`
+	ClusterID  string            `json:"clusterId"`
+	Namespaces []OrphanNamespace `json:"namespaces"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// OrphanReport lists the namespaces outside any project in every selected cluster.
+type OrphanReport struct {
+	Orphans  bool             `json:"orphans"`
+	Clusters []ClusterOrphans `json:"clusters"`
+}
+
+// HasErrors reports whether any cluster could not be inspected.
+func (r *OrphanReport) HasErrors() bool {
+	for _, c := range r.Clusters {
+		if c.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// FindOrphans lists the namespaces without a field.cattle.io/projectId annotation in each selected
+// cluster, leaving out namespaces matching any of the --exclude-namespaces patterns. Clusters that
+// cannot be inspected are recorded in the report instead of aborting the run.
+func FindOrphans(cfg *config.Config) (*OrphanReport, error) {
+	logger.Info("Looking for namespaces outside any project...")
+
+	clusters, err := SelectClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to select clusters: %v", err))
+		return nil, fmt.Errorf("failed to select clusters: %v", err)
+	}
+
+	now := time.Now()
+	report := &OrphanReport{Clusters: []ClusterOrphans{}}
+	for _, cluster := range clusters {
+		result := ClusterOrphans{Cluster: cluster.Name, ClusterID: cluster.ID, Namespaces: []OrphanNamespace{}}
+
+		namespaces, err := ListNamespaces(cfg, cluster.ID)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to inspect cluster %s: %v", cluster.Name, err))
+			result.Error = err.Error()
+		}
+		for _, ns := range namespaces {
+			if ns.ProjectID() != "" || ExcludedNamespace(ns.Name, cfg.ExcludeNamespaces) {
+				continue
+			}
+			result.Namespaces = append(result.Namespaces, OrphanNamespace{
+				Name:    ns.Name,
+				Created: ns.Created,
+				Age:     formatAge(now.Sub(ns.Created)),
+				Labels:  ns.Labels,
+			})
+		}
+		sort.Slice(result.Namespaces, func(i, j int) bool { return result.Namespaces[i].Name < result.Namespaces[j].Name })
+
+		logger.Info(fmt.Sprintf("Cluster %s: %d orphaned namespace(s)", cluster.Name, len(result.Namespaces)))
+		report.Orphans = report.Orphans || len(result.Namespaces) > 0
+		report.Clusters = append(report.Clusters, result)
+	}
+	return report, nil
+}
+
+// ExcludedNamespace reports whether name matches any of the glob patterns, e.g. "kube-*".
+func ExcludedNamespace(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// formatAge formats a duration the way kubectl shows ages: 45s, 12m, 5h, 3d4h.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	days := int(d.Hours()) / 24
+	if hours := int(d.Hours()) % 24; hours > 0 && days < 10 {
+		return fmt.Sprintf("%dd%dh", days, hours)
+	}
+	return fmt.Sprintf("%dd", days)
+}
+
+// Write prints the report as a table or, with format json, as a JSON document.
+func (r *OrphanReport) Write(w io.Writer, format string) error {
+	if format == config.OutputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	total := 0
+	for _, c := range r.Clusters {
+		switch {
+		case c.Error != "":
+			fmt.Fprintf(tw, "Cluster %s (%s): error: %s\n", c.Cluster, c.ClusterID, c.Error)
+			continue
+		case len(c.Namespaces) == 0:
+			fmt.Fprintf(tw, "Cluster %s (%s): no orphaned namespaces\n", c.Cluster, c.ClusterID)
+			continue
+		}
+		total += len(c.Namespaces)
+		fmt.Fprintf(tw, "Cluster %s (%s): %d orphaned namespace(s)\n", c.Cluster, c.ClusterID, len(c.Namespaces))
+		for _, ns := range c.Namespaces {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", ns.Name, ns.Age, formatLabels(ns.Labels))
+		}
+	}
+	fmt.Fprintf(tw, "%d orphaned namespace(s) in %d cluster(s)\n", total, len(r.Clusters))
+	return tw.Flush()
+}
+
+// formatLabels formats labels as sorted key=value pairs.
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
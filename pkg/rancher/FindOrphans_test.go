@@ -0,0 +1,76 @@
+package rancher
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestFindOrphans(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	cfg.ExcludeNamespaces = config.DefaultExcludeNamespaces
+	project := srv.AddProject(cluster.ID, "Team")
+	srv.AddNamespace(cluster.ID, "team", project.ID)
+	srv.AddNamespace(cluster.ID, "kube-system", "")
+	srv.AddNamespace(cluster.ID, "cattle-system", "")
+	srv.AddNamespace(cluster.ID, "default", "")
+	srv.AddNamespace(cluster.ID, "helm-release", "")
+	srv.UpdateNamespace(cluster.ID, "helm-release", func(ns *ranchertest.Namespace) {
+		ns.Created = time.Now().Add(-50 * time.Hour)
+		ns.Labels = map[string]string{"app.kubernetes.io/managed-by": "Helm"}
+	})
+
+	report, err := FindOrphans(cfg)
+	require.NoError(t, err)
+	assert.True(t, report.Orphans)
+	assert.False(t, report.HasErrors())
+	require.Len(t, report.Clusters, 1)
+	require.Len(t, report.Clusters[0].Namespaces, 1)
+	orphan := report.Clusters[0].Namespaces[0]
+	assert.Equal(t, "helm-release", orphan.Name)
+	assert.Equal(t, "2d2h", orphan.Age)
+	assert.Equal(t, "Helm", orphan.Labels["app.kubernetes.io/managed-by"])
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out, config.OutputText))
+	assert.Contains(t, out.String(), "helm-release  2d2h  app.kubernetes.io/managed-by=Helm")
+	assert.Contains(t, out.String(), "1 orphaned namespace(s) in 1 cluster(s)")
+
+	out.Reset()
+	require.NoError(t, report.Write(&out, config.OutputJSON))
+	var decoded OrphanReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, "helm-release", decoded.Clusters[0].Namespaces[0].Name)
+
+	// Without exclusions every unassigned namespace is reported; failures are per cluster.
+	cfg.ExcludeNamespaces = nil
+	report, err = FindOrphans(cfg)
+	require.NoError(t, err)
+	assert.Len(t, report.Clusters[0].Namespaces, 4)
+
+	srv.Fail("GET", "/k8s/clusters/", http.StatusInternalServerError)
+	report, err = FindOrphans(cfg)
+	require.NoError(t, err)
+	assert.True(t, report.HasErrors())
+	assert.False(t, report.Orphans)
+}
+
+func TestFormatAge(t *testing.T) {
+	for d, expected := range map[time.Duration]string{
+		30 * time.Second:     "30s",
+		90 * time.Minute:     "1h",
+		25 * time.Hour:       "1d1h",
+		48 * time.Hour:       "2d",
+		300 * 24 * time.Hour: "300d",
+	} {
+		assert.Equal(t, expected, formatAge(d), d.String())
+	}
+}
@@ -1,40 +1,38 @@
 package rancher
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
-	"time"
 
+	"github.com/supporttools/rancher-projects/pkg/audit"
 	"github.com/supporttools/rancher-projects/pkg/config"
 )
 
 // GenerateKubeconfig creates a kubeconfig file for a specified cluster.
-func GenerateKubeconfig(cfg *config.Config, kubeconfigFile, clusterID string) error {
+func GenerateKubeconfig(cfg *config.Config, kubeconfigFile, clusterID string) (err error) {
 	logger.Info("Generating kubeconfig...")
 
 	// Construct the request URL for generating kubeconfig.
 	url := fmt.Sprintf("%s/v3/clusters/%s?action=generateKubeconfig", cfg.RancherServerURL, clusterID)
 	logger.Debug(fmt.Sprintf("Generated request URL for kubeconfig: %s", url))
 
-	req, err := http.NewRequest("POST", url, http.NoBody)
+	req, err := NewRequest(cfg, "POST", url, http.NoBody)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
 		return fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	// Encode the authentication credentials and set the request headers.
-	authHeader := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", cfg.RancherAccessKey, cfg.RancherSecretKey)))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", authHeader))
-
 	// Send the request using the HTTP client.
-	client := &http.Client{
-		Timeout: time.Second * 10, // 10 seconds timeout
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
 	}
 
+	entry := audit.Entry{Action: audit.ActionGenerateKubeconfig, ClusterID: clusterID, Object: "kubeconfig/" + clusterID, After: kubeconfigFile}
+	defer func() { auditChange(cfg, &entry, err) }()
+
 	logger.Info("Sending POST request to generate kubeconfig...")
 	resp, err := client.Do(req)
 	if err != nil {
@@ -77,5 +75,9 @@ func GenerateKubeconfig(cfg *config.Config, kubeconfigFile, clusterID string) er
 	}
 
 	logger.Info(fmt.Sprintf("Kubeconfig file successfully generated: %s", kubeconfigFile))
+
+	if !ServerCapabilities(cfg).KubeconfigToken {
+		logger.Warn(fmt.Sprintf("Rancher does not put tokens in generated kubeconfigs (kubeconfig-generate-token is false); %s needs the rancher CLI to log in", kubeconfigFile))
+	}
 	return nil
 }
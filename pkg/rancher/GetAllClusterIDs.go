@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/supporttools/rancher-projects/pkg/config"
 )
@@ -17,17 +16,15 @@ func GetAllClusterIDs(cfg *config.Config) error {
 	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
 
 	// Updated to use http.NoBody
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
 		return fmt.Errorf("failed to create HTTP request: %v", err)
 	}
 
-	req.SetBasicAuth(cfg.RancherAccessKey, cfg.RancherSecretKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{
-		Timeout: time.Second * 10, // 10 seconds timeout
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
 	}
 
 	logger.Info("Sending GET request to fetch cluster IDs...")
@@ -50,21 +47,23 @@ func GetAllClusterIDs(cfg *config.Config) error {
 	}
 	logger.Debug(fmt.Sprintf("Received response body: %s", string(body)))
 
-	var clusters []map[string]interface{}
+	var clusters struct {
+		Data []map[string]interface{} `json:"data"`
+	}
 	if err = json.Unmarshal(body, &clusters); err != nil {
 		logger.Error(fmt.Sprintf("Failed to parse cluster IDs: %v", err))
 		return fmt.Errorf("failed to parse cluster IDs: %v", err)
 	}
 
-	cfg.ClusterIDs = make([]string, len(clusters))
-	for i, cluster := range clusters {
+	cfg.ClusterIDs = make([]string, 0, len(clusters.Data))
+	for _, cluster := range clusters.Data {
 		clusterName, okName := cluster["name"].(string)
 		clusterID, okID := cluster["id"].(string)
 		if !okName || !okID {
 			logger.Warn(fmt.Sprintf("Skipping cluster due to missing or invalid name/id: %v", cluster))
 			continue // Skip if types do not match expectations
 		}
-		cfg.ClusterIDs[i] = fmt.Sprintf("%s:%s", clusterName, clusterID)
+		cfg.ClusterIDs = append(cfg.ClusterIDs, fmt.Sprintf("%s:%s", clusterName, clusterID))
 	}
 
 	logger.Info(fmt.Sprintf("Retrieved Cluster IDs: %v", cfg.ClusterIDs))
@@ -0,0 +1,22 @@
+package rancher
+
+import (
+	"fmt"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// GetCluster fetches a cluster by name. A missing cluster is reported with an error wrapping ErrNotFound.
+func GetCluster(cfg *config.Config, clusterName string) (*Cluster, error) {
+	logger.Info(fmt.Sprintf("Fetching cluster %s...", clusterName))
+
+	clusters, err := discoverClusters(cfg, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) == 0 {
+		logger.Error(fmt.Sprintf("No clusters found with name: %s", clusterName))
+		return nil, fmt.Errorf("no clusters found with name: %s: %w", clusterName, ErrNotFound)
+	}
+	return &clusters[0], nil
+}
@@ -1,11 +1,9 @@
 package rancher
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/supporttools/rancher-projects/pkg/config"
 )
@@ -14,22 +12,19 @@ import (
 func GetClusterID(cfg *config.Config) (string, error) {
 	logger.Info(fmt.Sprintf("Fetching cluster ID for cluster: %s", cfg.ClusterName))
 
-	url := fmt.Sprintf("%s/v3/clusters?name=%s", cfg.RancherServerURL, cfg.ClusterName)
+	url := clusterQuery(cfg, cfg.ClusterName)
 	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
 
 	// Updated to use http.NoBody
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
 		return "", fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	authHeader := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", cfg.RancherAccessKey, cfg.RancherSecretKey)))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", authHeader))
-
-	client := &http.Client{
-		Timeout: time.Second * 10, // 10 seconds timeout
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return "", err
 	}
 
 	logger.Info("Sending GET request to retrieve cluster ID...")
@@ -58,7 +53,7 @@ func GetClusterID(cfg *config.Config) (string, error) {
 
 	if len(data.Data) == 0 {
 		logger.Error(fmt.Sprintf("Failed to find cluster ID for cluster name: %s", cfg.ClusterName))
-		return "", fmt.Errorf("failed to find cluster ID for cluster name: %s", cfg.ClusterName)
+		return "", fmt.Errorf("failed to find cluster ID for cluster name: %s: %w", cfg.ClusterName, ErrNotFound)
 	}
 
 	clusterID := data.Data[0].ID
@@ -0,0 +1,53 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// GetNamespace returns the metadata of a namespace. The error wraps ErrNotFound when the namespace does not exist.
+func GetNamespace(cfg *config.Config, clusterID, namespace string) (Namespace, error) {
+	logger.Info(fmt.Sprintf("Fetching namespace %s in cluster %s...", namespace, clusterID))
+
+	url := fmt.Sprintf("%s/k8s/clusters/%s/v1/namespaces/%s", cfg.RancherServerURL, clusterID, namespace)
+	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
+
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request for namespace %s: %v", namespace, err))
+		return Namespace{}, fmt.Errorf("failed to create HTTP request for namespace %s: %v", namespace, err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return Namespace{}, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to fetch namespace %s: %v", namespace, err))
+		return Namespace{}, fmt.Errorf("failed to fetch namespace %s: %v", namespace, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return Namespace{}, fmt.Errorf("namespace %s not found in cluster %s: %w", namespace, clusterID, ErrNotFound)
+	default:
+		logger.Error(fmt.Sprintf("Unexpected status code %d while fetching namespace %s", resp.StatusCode, namespace))
+		return Namespace{}, fmt.Errorf("unexpected status code %d while fetching namespace %s", resp.StatusCode, namespace)
+	}
+
+	var data struct {
+		Metadata Namespace `json:"metadata"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		logger.Error(fmt.Sprintf("Failed to decode JSON response: %v", err))
+		return Namespace{}, fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+	return data.Metadata, nil
+}
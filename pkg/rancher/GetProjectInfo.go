@@ -1,11 +1,9 @@
 package rancher
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/supporttools/rancher-projects/pkg/config"
 )
@@ -14,22 +12,32 @@ import (
 func GetProjectInfo(cfg *config.Config, clusterID, projectName string) (string, error) {
 	logger.Info(fmt.Sprintf("Fetching project info for project: %s in cluster: %s", projectName, clusterID))
 
-	url := fmt.Sprintf("%s/v3/projects?clusterId=%s&name=%s", cfg.RancherServerURL, clusterID, projectName)
+	if ProjectAPI(cfg) == config.APISteve {
+		project, err := findSteveProject(cfg, clusterID, projectName)
+		if err != nil {
+			return "", err
+		}
+		if project == nil {
+			logger.Error(fmt.Sprintf("Failed to find project info for project name: %s", projectName))
+			return "", fmt.Errorf("failed to find project info for project name: %s: %w", projectName, ErrNotFound)
+		}
+		logger.Info(fmt.Sprintf("Successfully retrieved project ID: %s", project.Id))
+		return project.Id, nil
+	}
+
+	url := projectQuery(cfg, clusterID, projectName)
 	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
 
 	// Updated to use http.NoBody instead of nil
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
 		return "", fmt.Errorf("failed to create HTTP request: %v", err)
 	}
 
-	authHeader := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", cfg.RancherAccessKey, cfg.RancherSecretKey)))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", authHeader))
-
-	client := &http.Client{
-		Timeout: time.Second * 10, // 10 seconds timeout
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return "", err
 	}
 
 	logger.Info("Sending GET request to retrieve project info...")
@@ -58,7 +66,7 @@ func GetProjectInfo(cfg *config.Config, clusterID, projectName string) (string,
 
 	if len(data.Data) == 0 {
 		logger.Error(fmt.Sprintf("Failed to find project info for project name: %s", projectName))
-		return "", fmt.Errorf("failed to find project info for project name: %s", projectName)
+		return "", fmt.Errorf("failed to find project info for project name: %s: %w", projectName, ErrNotFound)
 	}
 
 	projectID := data.Data[0].ID
@@ -0,0 +1,49 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// GetSetting returns the value of a Rancher setting, e.g. server-version. The error wraps
+// ErrNotFound when the server has no such setting.
+func GetSetting(cfg *config.Config, name string) (string, error) {
+	url := fmt.Sprintf("%s/v3/settings/%s", cfg.RancherServerURL, name)
+	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
+
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return "", fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get setting %s: %v", name, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", fmt.Errorf("setting %s: %w", name, ErrNotFound)
+	default:
+		return "", fmt.Errorf("failed to get setting %s, status code: %d", name, resp.StatusCode)
+	}
+
+	var setting struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&setting); err != nil {
+		return "", fmt.Errorf("failed to decode setting %s: %v", name, err)
+	}
+	return setting.Value, nil
+}
@@ -0,0 +1,50 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// GetSteveProject returns the project with the given <clusterID>:<projectID> from the Steve API.
+// The error wraps ErrNotFound when the project does not exist.
+func GetSteveProject(cfg *config.Config, projectID string) (*Project, error) {
+	logger.Debug(fmt.Sprintf("Getting project %s...", projectID))
+
+	req, err := NewRequest(cfg, "GET", steveProjectURL(cfg, projectID), http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return nil, fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("project %s: %w", projectID, ErrNotFound)
+	default:
+		logger.Error(fmt.Sprintf("Failed to get project %s, status code: %d", projectID, resp.StatusCode))
+		return nil, fmt.Errorf("failed to get project %s, status code: %d", projectID, resp.StatusCode)
+	}
+
+	var project steveProject
+	if err := json.NewDecoder(resp.Body).Decode(&project); err != nil {
+		logger.Error(fmt.Sprintf("Failed to decode JSON response: %v", err))
+		return nil, fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+	p := project.project()
+	return &p, nil
+}
@@ -0,0 +1,140 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// ListClusters returns every downstream cluster known to Rancher, discovered through the API
+// selected by --cluster-api.
+func ListClusters(cfg *config.Config) ([]Cluster, error) {
+	logger.Info("Listing clusters...")
+	return discoverClusters(cfg, "")
+}
+
+// discoverClusters returns the clusters with the given name, or every cluster when name is empty.
+// When the API is auto the Steve API is tried first and /v3/clusters is used when it fails.
+func discoverClusters(cfg *config.Config, name string) ([]Cluster, error) {
+	switch ClusterAPI(cfg) {
+	case config.APINorman:
+		return listNormanClusters(cfg, name)
+	case config.APISteve:
+		return listSteveClusters(cfg, name)
+	}
+
+	clusters, err := listSteveClusters(cfg, name)
+	if err == nil {
+		return clusters, nil
+	}
+	logger.Warn(fmt.Sprintf("Failed to discover clusters through the Steve API, falling back to /v3/clusters: %v", err))
+	return listNormanClusters(cfg, name)
+}
+
+func listSteveClusters(cfg *config.Config, name string) ([]Cluster, error) {
+	clusters, err := ListSteveClusters(cfg)
+	if err != nil || name == "" {
+		return clusters, err
+	}
+
+	var named []Cluster
+	for _, cluster := range clusters {
+		if cluster.Name == name {
+			named = append(named, cluster)
+		}
+	}
+	return named, nil
+}
+
+func listNormanClusters(cfg *config.Config, name string) ([]Cluster, error) {
+	if name != "" {
+		return fetchClusters(cfg, clusterQuery(cfg, name))
+	}
+	return fetchClusters(cfg, fmt.Sprintf("%s/v3/clusters", cfg.RancherServerURL))
+}
+
+// clusterResource is a cluster in the shape returned by /v3/clusters.
+type clusterResource struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Provider    string            `json:"provider"`
+	Driver      string            `json:"driver"`
+	State       string            `json:"state"`
+	NodeCount   int               `json:"nodeCount"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	Created     time.Time         `json:"created"`
+	Version     struct {
+		GitVersion string `json:"gitVersion"`
+	} `json:"version"`
+	Capacity map[string]string `json:"capacity"`
+}
+
+func (r clusterResource) cluster() Cluster {
+	return Cluster{
+		ID:                r.ID,
+		Name:              r.Name,
+		Provider:          r.Provider,
+		Driver:            r.Driver,
+		State:             r.State,
+		KubernetesVersion: r.Version.GitVersion,
+		NodeCount:         r.NodeCount,
+		CPU:               r.Capacity["cpu"],
+		Memory:            r.Capacity["memory"],
+		Labels:            r.Labels,
+		Annotations:       r.Annotations,
+		Created:           r.Created,
+	}
+}
+
+// fetchClusters returns the clusters of a /v3/clusters collection URL.
+func fetchClusters(cfg *config.Config, url string) ([]Cluster, error) {
+	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
+
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to retrieve clusters: %v", err))
+		return nil, fmt.Errorf("failed to retrieve clusters: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Failed to retrieve clusters. Status code: %d", resp.StatusCode))
+		return nil, fmt.Errorf("failed to retrieve clusters. Status code: %d", resp.StatusCode)
+	}
+
+	var data struct {
+		Data []clusterResource `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		logger.Error(fmt.Sprintf("Failed to decode JSON response: %v", err))
+		return nil, fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+
+	clusters := make([]Cluster, 0, len(data.Data))
+	for _, resource := range data.Data {
+		clusters = append(clusters, resource.cluster())
+	}
+	logger.Debug(fmt.Sprintf("Found %d cluster(s)", len(clusters)))
+	return clusters, nil
+}
+
+// clusterQuery returns the /v3/clusters URL listing the clusters with the given name.
+func clusterQuery(cfg *config.Config, clusterName string) string {
+	return fmt.Sprintf("%s/v3/clusters?name=%s", cfg.RancherServerURL, url.QueryEscape(clusterName))
+}
@@ -0,0 +1,57 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// ListNamespaces returns all namespaces of a cluster through the Rancher cluster proxy.
+func ListNamespaces(cfg *config.Config, clusterID string) ([]Namespace, error) {
+	logger.Info(fmt.Sprintf("Listing namespaces in cluster %s...", clusterID))
+
+	url := fmt.Sprintf("%s/k8s/clusters/%s/v1/namespaces", cfg.RancherServerURL, clusterID)
+	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
+
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return nil, fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Failed to list namespaces, status code: %d", resp.StatusCode))
+		return nil, fmt.Errorf("failed to list namespaces, status code: %d", resp.StatusCode)
+	}
+
+	var data struct {
+		Data []struct {
+			Metadata Namespace `json:"metadata"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		logger.Error(fmt.Sprintf("Failed to decode JSON response: %v", err))
+		return nil, fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+
+	namespaces := make([]Namespace, 0, len(data.Data))
+	for _, item := range data.Data {
+		namespaces = append(namespaces, item.Metadata)
+	}
+	logger.Debug(fmt.Sprintf("Found %d namespace(s) in cluster %s", len(namespaces), clusterID))
+	return namespaces, nil
+}
@@ -0,0 +1,47 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// ListProjectMembers returns the role template bindings of a project.
+func ListProjectMembers(cfg *config.Config, projectID string) ([]ProjectMember, error) {
+	logger.Debug(fmt.Sprintf("Listing members of project %s...", projectID))
+
+	url := fmt.Sprintf("%s/v3/projectroletemplatebindings?projectId=%s", cfg.RancherServerURL, projectID)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return nil, fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Failed to list members of project %s, status code: %d", projectID, resp.StatusCode))
+		return nil, fmt.Errorf("failed to list members of project %s, status code: %d", projectID, resp.StatusCode)
+	}
+
+	var data struct {
+		Data []ProjectMember `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		logger.Error(fmt.Sprintf("Failed to decode JSON response: %v", err))
+		return nil, fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+	return data.Data, nil
+}
@@ -0,0 +1,63 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// ListProjects returns all projects of a cluster.
+func ListProjects(cfg *config.Config, clusterID string) ([]Project, error) {
+	if ProjectAPI(cfg) == config.APISteve {
+		return ListSteveProjects(cfg, clusterID)
+	}
+	logger.Info(fmt.Sprintf("Listing projects in cluster %s...", clusterID))
+
+	url := projectQuery(cfg, clusterID, "")
+	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
+
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return nil, fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Failed to list projects, status code: %d", resp.StatusCode))
+		return nil, fmt.Errorf("failed to list projects, status code: %d", resp.StatusCode)
+	}
+
+	var data RancherResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		logger.Error(fmt.Sprintf("Failed to decode JSON response: %v", err))
+		return nil, fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+
+	logger.Debug(fmt.Sprintf("Found %d project(s) in cluster %s", len(data.Data), clusterID))
+	return data.Data, nil
+}
+
+// projectQuery returns the /v3/projects URL listing the projects of a cluster, only those with the
+// given name when it is not empty.
+func projectQuery(cfg *config.Config, clusterID, projectName string) string {
+	query := url.Values{"clusterId": {clusterID}}
+	if projectName != "" {
+		query.Set("name", projectName)
+	}
+	return fmt.Sprintf("%s/v3/projects?%s", cfg.RancherServerURL, query.Encode())
+}
@@ -0,0 +1,182 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// steveMetadata is the metadata of a Steve object, including the state summary Steve adds.
+type steveMetadata struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	Labels            map[string]string `json:"labels"`
+	Annotations       map[string]string `json:"annotations"`
+	CreationTimestamp time.Time         `json:"creationTimestamp"`
+	State             struct {
+		Name string `json:"name"`
+	} `json:"state"`
+}
+
+// managementCluster is a management.cattle.io/v3 Cluster as returned by the Steve API.
+type managementCluster struct {
+	ID       string        `json:"id"`
+	Metadata steveMetadata `json:"metadata"`
+	Spec     struct {
+		DisplayName string `json:"displayName"`
+	} `json:"spec"`
+	Status struct {
+		Provider  string            `json:"provider"`
+		Driver    string            `json:"driver"`
+		NodeCount int               `json:"nodeCount"`
+		Capacity  map[string]string `json:"capacity"`
+		Version   struct {
+			GitVersion string `json:"gitVersion"`
+		} `json:"version"`
+	} `json:"status"`
+}
+
+// provisioningCluster is a provisioning.cattle.io/v1 Cluster as returned by the Steve API.
+type provisioningCluster struct {
+	Metadata steveMetadata `json:"metadata"`
+	Spec     struct {
+		KubernetesVersion string `json:"kubernetesVersion"`
+		RKEConfig         *struct {
+			MachinePools []provisioningMachinePool `json:"machinePools"`
+		} `json:"rkeConfig"`
+	} `json:"spec"`
+	Status struct {
+		// ClusterName is the ID of the matching management cluster, e.g. c-m-abcd1234.
+		ClusterName string `json:"clusterName"`
+	} `json:"status"`
+}
+
+// provisioningMachinePool is a machine pool in the rkeConfig of a provisioning.cattle.io cluster.
+type provisioningMachinePool struct {
+	Name             string `json:"name"`
+	Quantity         *int   `json:"quantity"`
+	EtcdRole         bool   `json:"etcdRole"`
+	ControlPlaneRole bool   `json:"controlPlaneRole"`
+	WorkerRole       bool   `json:"workerRole"`
+	MachineConfigRef struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	} `json:"machineConfigRef"`
+}
+
+func (p provisioningMachinePool) machinePool() MachinePool {
+	pool := MachinePool{Name: p.Name, Quantity: 1}
+	// An unset quantity means a single machine, as in Rancher.
+	if p.Quantity != nil {
+		pool.Quantity = *p.Quantity
+	}
+	if p.EtcdRole {
+		pool.Roles = append(pool.Roles, "etcd")
+	}
+	if p.ControlPlaneRole {
+		pool.Roles = append(pool.Roles, "control-plane")
+	}
+	if p.WorkerRole {
+		pool.Roles = append(pool.Roles, "worker")
+	}
+	if p.MachineConfigRef.Name != "" {
+		pool.MachineConfig = p.MachineConfigRef.Kind + "/" + p.MachineConfigRef.Name
+	}
+	return pool
+}
+
+// ListSteveClusters returns every downstream cluster from the management.cattle.io clusters of the
+// Steve API (Rancher v2.6+), completed with the fleet workspace and machine pools of the matching
+// provisioning.cattle.io cluster. Clusters without a provisioning cluster are returned without them.
+func ListSteveClusters(cfg *config.Config) ([]Cluster, error) {
+	var management struct {
+		Data []managementCluster `json:"data"`
+	}
+	if err := getSteve(cfg, "management.cattle.io.clusters", &management); err != nil {
+		return nil, err
+	}
+
+	var provisioning struct {
+		Data []provisioningCluster `json:"data"`
+	}
+	if err := getSteve(cfg, "provisioning.cattle.io.clusters", &provisioning); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to list provisioning clusters, fleet workspaces and machine pools are unknown: %v", err))
+	}
+	byID := make(map[string]provisioningCluster, len(provisioning.Data))
+	for _, p := range provisioning.Data {
+		byID[p.Status.ClusterName] = p
+	}
+
+	clusters := make([]Cluster, 0, len(management.Data))
+	for _, m := range management.Data {
+		cluster := Cluster{
+			ID:                m.ID,
+			Name:              m.Spec.DisplayName,
+			Provider:          m.Status.Provider,
+			Driver:            m.Status.Driver,
+			State:             m.Metadata.State.Name,
+			KubernetesVersion: m.Status.Version.GitVersion,
+			NodeCount:         m.Status.NodeCount,
+			CPU:               m.Status.Capacity["cpu"],
+			Memory:            m.Status.Capacity["memory"],
+			Labels:            m.Metadata.Labels,
+			Annotations:       m.Metadata.Annotations,
+			Created:           m.Metadata.CreationTimestamp,
+		}
+		if cluster.Name == "" {
+			cluster.Name = m.Metadata.Name
+		}
+
+		if p, ok := byID[m.ID]; ok {
+			cluster.FleetWorkspace = p.Metadata.Namespace
+			if cluster.KubernetesVersion == "" {
+				cluster.KubernetesVersion = p.Spec.KubernetesVersion
+			}
+			if p.Spec.RKEConfig != nil {
+				for _, pool := range p.Spec.RKEConfig.MachinePools {
+					cluster.MachinePools = append(cluster.MachinePools, pool.machinePool())
+				}
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	logger.Debug(fmt.Sprintf("Found %d cluster(s) through the Steve API", len(clusters)))
+	return clusters, nil
+}
+
+// getSteve decodes the collection of a resource type of the Steve API on the Rancher server, e.g.
+// management.cattle.io.clusters, into v.
+func getSteve(cfg *config.Config, resourceType string, v interface{}) error {
+	url := fmt.Sprintf("%s/v1/%s", cfg.RancherServerURL, resourceType)
+	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
+
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %v", resourceType, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to list %s. Status code: %d", resourceType, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", resourceType, err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package rancher
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestListSteveClusters(t *testing.T) {
+	srv, cfg, downstream := newTestServer(t)
+	custom := srv.AddCluster(ranchertest.Cluster{
+		Name:              "custom",
+		Provider:          "rke2",
+		FleetWorkspace:    "fleet-edge",
+		KubernetesVersion: "v1.28.9+rke2r1",
+		NodeCount:         4,
+		CPU:               "16",
+		Memory:            "64Gi",
+		Labels:            map[string]string{"env": "edge"},
+		MachinePools: []ranchertest.MachinePool{
+			{Name: "cp", Quantity: 1, EtcdRole: true, ControlPlaneRole: true, MachineConfig: "Amazonec2Config/nc-cp"},
+			{Name: "workers", Quantity: 3, WorkerRole: true, MachineConfig: "Amazonec2Config/nc-workers"},
+		},
+	})
+
+	clusters, err := ListSteveClusters(cfg)
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+
+	assert.Equal(t, downstream.ID, clusters[0].ID)
+	assert.Equal(t, "downstream", clusters[0].Name)
+	assert.Equal(t, "fleet-default", clusters[0].FleetWorkspace)
+	assert.Empty(t, clusters[0].MachinePools)
+
+	assert.Equal(t, Cluster{
+		ID:                custom.ID,
+		Name:              "custom",
+		Provider:          "rke2",
+		State:             "active",
+		KubernetesVersion: "v1.28.9+rke2r1",
+		NodeCount:         4,
+		CPU:               "16",
+		Memory:            "64Gi",
+		Labels:            map[string]string{"env": "edge"},
+		Annotations:       map[string]string{},
+		Created:           custom.Created,
+		FleetWorkspace:    "fleet-edge",
+		MachinePools: []MachinePool{
+			{Name: "cp", Quantity: 1, Roles: []string{"etcd", "control-plane"}, MachineConfig: "Amazonec2Config/nc-cp"},
+			{Name: "workers", Quantity: 3, Roles: []string{"worker"}, MachineConfig: "Amazonec2Config/nc-workers"},
+		},
+	}, clusters[1])
+}
+
+func TestListClustersFallsBackToNorman(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	srv.Fail("GET", "/v1/", http.StatusNotFound)
+
+	clusters, err := ListClusters(cfg)
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, cluster.ID, clusters[0].ID)
+	assert.Empty(t, clusters[0].FleetWorkspace)
+	assert.Equal(t, 1, srv.CountRequests("GET", "/v3/clusters"))
+
+	found, err := GetCluster(cfg, "downstream")
+	require.NoError(t, err)
+	assert.Equal(t, cluster.ID, found.ID)
+
+	cfg.ClusterAPI = config.APISteve
+	_, err = ListClusters(cfg)
+	assert.ErrorContains(t, err, "failed to list management.cattle.io.clusters. Status code: 404")
+}
+
+func TestListClustersNorman(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	cfg.ClusterAPI = config.APINorman
+
+	clusters, err := ListClusters(cfg)
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.Zero(t, srv.CountRequests("GET", "/v1/"))
+}
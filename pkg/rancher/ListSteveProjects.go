@@ -0,0 +1,83 @@
+package rancher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// steveProject is a management.cattle.io/v3 Project as returned by the Steve API. Projects live in
+// the namespace named after their cluster ID.
+type steveProject struct {
+	ID       string        `json:"id"`
+	Metadata steveMetadata `json:"metadata"`
+	Spec     struct {
+		DisplayName                   string                 `json:"displayName"`
+		Description                   string                 `json:"description"`
+		ClusterName                   string                 `json:"clusterName"`
+		ResourceQuota                 map[string]interface{} `json:"resourceQuota"`
+		NamespaceDefaultResourceQuota map[string]interface{} `json:"namespaceDefaultResourceQuota"`
+		ContainerDefaultResourceLimit map[string]interface{} `json:"containerDefaultResourceLimit"`
+	} `json:"spec"`
+}
+
+// project returns the project in the shape of /v3/projects, with an ID of the form <clusterID>:<projectID>.
+func (p steveProject) project() Project {
+	return Project{
+		Id:          p.Metadata.Namespace + ":" + p.Metadata.Name,
+		Type:        "project",
+		BaseType:    "project",
+		Name:        p.Spec.DisplayName,
+		ClusterId:   p.Metadata.Namespace,
+		Description: p.Spec.Description,
+		Labels:      p.Metadata.Labels,
+		Annotations: p.Metadata.Annotations,
+		Created:     p.Metadata.CreationTimestamp,
+		State:       p.Metadata.State.Name,
+
+		ResourceQuota:                 p.Spec.ResourceQuota,
+		NamespaceDefaultResourceQuota: p.Spec.NamespaceDefaultResourceQuota,
+		ContainerDefaultResourceLimit: p.Spec.ContainerDefaultResourceLimit,
+	}
+}
+
+// ListSteveProjects returns all projects of a cluster from the management.cattle.io Project
+// resources of the Steve API.
+func ListSteveProjects(cfg *config.Config, clusterID string) ([]Project, error) {
+	logger.Info(fmt.Sprintf("Listing projects in cluster %s...", clusterID))
+
+	var data struct {
+		Data []steveProject `json:"data"`
+	}
+	if err := getSteve(cfg, "management.cattle.io.projects/"+clusterID, &data); err != nil {
+		logger.Error(fmt.Sprintf("Failed to list projects: %v", err))
+		return nil, err
+	}
+
+	projects := make([]Project, 0, len(data.Data))
+	for _, p := range data.Data {
+		projects = append(projects, p.project())
+	}
+	logger.Debug(fmt.Sprintf("Found %d project(s) in cluster %s", len(projects), clusterID))
+	return projects, nil
+}
+
+// findSteveProject returns the project of a cluster with the given display name, or nil when there is none.
+func findSteveProject(cfg *config.Config, clusterID, projectName string) (*Project, error) {
+	projects, err := ListSteveProjects(cfg, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range projects {
+		if projects[i].Name == projectName {
+			return &projects[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// steveProjectURL returns the Steve URL of a project given as <clusterID>:<projectID>.
+func steveProjectURL(cfg *config.Config, projectID string) string {
+	return fmt.Sprintf("%s/v1/management.cattle.io.projects/%s", cfg.RancherServerURL, strings.Replace(projectID, ":", "/", 1))
+}
@@ -2,31 +2,48 @@ package rancher
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/supporttools/rancher-projects/pkg/config"
 )
 
-// MainProject processes a project within a specified cluster, verifying the project and namespace, and optionally generating a kubeconfig.
+// MainProject processes a project within a specified cluster: it creates the project and namespace when
+// requested, applies the project template and project settings, assigns the namespace to the project
+// and optionally generates a kubeconfig.
 func MainProject(cfg *config.Config, clusterID string) error {
 	logger.Info("Starting project verification...")
 
-	logger.Info(fmt.Sprintf("Verifying project: %s", cfg.ProjectName))
-	if err := VerifyProject(cfg, clusterID, cfg.ProjectName); err != nil {
-		logger.Error(fmt.Sprintf("Error verifying project '%s': %v", cfg.ProjectName, err))
-		return fmt.Errorf("error verifying project '%s': %v", cfg.ProjectName, err)
-	}
-
-	logger.Info(fmt.Sprintf("Verifying namespace: %s", cfg.Namespace))
 	if cfg.ProjectName != "" {
-		if err := VerifyNamespace(cfg, clusterID, cfg.Namespace); err != nil {
-			logger.Error(fmt.Sprintf("Error verifying namespace '%s': %v", cfg.Namespace, err))
-			return fmt.Errorf("error verifying namespace '%s': %v", cfg.Namespace, err)
+		if cfg.CreateProject {
+			logger.Info(fmt.Sprintf("Creating project: %s", cfg.ProjectName))
+			if err := CreateProject(cfg, clusterID, cfg.ProjectName); err != nil {
+				logger.Error(fmt.Sprintf("Error creating project '%s': %v", cfg.ProjectName, err))
+				return fmt.Errorf("error creating project '%s': %v", cfg.ProjectName, err)
+			}
+		}
+
+		logger.Info(fmt.Sprintf("Verifying project: %s", cfg.ProjectName))
+		if err := VerifyProject(cfg, clusterID, cfg.ProjectName); err != nil {
+			logger.Error(fmt.Sprintf("Error verifying project '%s': %v", cfg.ProjectName, err))
+			return fmt.Errorf("error verifying project '%s': %v", cfg.ProjectName, err)
+		}
+
+		if cfg.ProjectTemplate != "" || cfg.NetworkIsolation || cfg.PodSecurity != "" {
+			if err := configureProject(cfg, clusterID); err != nil {
+				return err
+			}
+		}
+
+		if cfg.Namespace != "" {
+			if err := assignNamespace(cfg, clusterID); err != nil {
+				return err
+			}
 		}
 	}
 
-	logger.Info(fmt.Sprintf("Creating kubeconfig for cluster '%s'...", clusterID))
 	if cfg.CreateKubeconfig {
-		if err := GenerateKubeconfig(cfg, cfg.KubeconfigFile, clusterID); err != nil {
+		logger.Info(fmt.Sprintf("Creating kubeconfig for cluster '%s'...", clusterID))
+		if err := GenerateKubeconfig(cfg, KubeconfigPath(cfg, cfg.ClusterName), clusterID); err != nil {
 			logger.Error(fmt.Sprintf("Error generating kubeconfig for cluster '%s': %v", clusterID, err))
 			return fmt.Errorf("error generating kubeconfig for cluster '%s': %v", clusterID, err)
 		}
@@ -34,3 +51,88 @@ func MainProject(cfg *config.Config, clusterID string) error {
 
 	return nil
 }
+
+// configureProject applies the configured project template, enables network isolation and sets the
+// Pod Security level of the configured project, as requested. --pod-security wins over the level
+// of the template. The level is set before any namespace is assigned so the namespace gets its labels.
+func configureProject(cfg *config.Config, clusterID string) error {
+	projectID, err := GetProjectInfo(cfg, clusterID, cfg.ProjectName)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error getting project ID for '%s': %v", cfg.ProjectName, err))
+		return fmt.Errorf("error getting project ID for '%s': %v", cfg.ProjectName, err)
+	}
+	if cfg.ProjectTemplate != "" {
+		tmpl := cfg.ProjectTemplates[cfg.ProjectTemplate]
+		if cfg.PodSecurity != "" {
+			tmpl.PodSecurity = cfg.PodSecurity
+		}
+		if err := ApplyProjectTemplate(cfg, clusterID, projectID, tmpl); err != nil {
+			logger.Error(fmt.Sprintf("Error applying project template '%s' to project '%s': %v", cfg.ProjectTemplate, cfg.ProjectName, err))
+			return fmt.Errorf("error applying project template '%s' to project '%s': %v", cfg.ProjectTemplate, cfg.ProjectName, err)
+		}
+	} else if cfg.PodSecurity != "" {
+		if err := SetProjectPodSecurity(cfg, clusterID, projectID, cfg.PodSecurity); err != nil {
+			logger.Error(fmt.Sprintf("Error setting Pod Security level of project '%s': %v", cfg.ProjectName, err))
+			return fmt.Errorf("error setting Pod Security level of project '%s': %v", cfg.ProjectName, err)
+		}
+	}
+	if cfg.NetworkIsolation {
+		if err := EnableProjectNetworkIsolation(cfg, clusterID, projectID); err != nil {
+			logger.Error(fmt.Sprintf("Error enabling network isolation for project '%s': %v", cfg.ProjectName, err))
+			return fmt.Errorf("error enabling network isolation for project '%s': %v", cfg.ProjectName, err)
+		}
+	}
+	return nil
+}
+
+// assignNamespace creates the configured namespace when requested and assigns it to the configured
+// project. A namespace it creates gets the default NetworkPolicies when requested.
+func assignNamespace(cfg *config.Config, clusterID string) error {
+	projectID, err := GetProjectInfo(cfg, clusterID, cfg.ProjectName)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error getting project ID for '%s': %v", cfg.ProjectName, err))
+		return fmt.Errorf("error getting project ID for '%s': %v", cfg.ProjectName, err)
+	}
+
+	created := false
+	if cfg.CreateNamespace {
+		logger.Info(fmt.Sprintf("Creating namespace: %s", cfg.Namespace))
+		if created, err = createNamespace(cfg, clusterID, cfg.Namespace); err != nil {
+			logger.Error(fmt.Sprintf("Error creating namespace '%s': %v", cfg.Namespace, err))
+			return fmt.Errorf("error creating namespace '%s': %v", cfg.Namespace, err)
+		}
+	}
+
+	logger.Info(fmt.Sprintf("Verifying namespace: %s", cfg.Namespace))
+	if err := VerifyNamespace(cfg, clusterID, cfg.Namespace); err != nil {
+		logger.Error(fmt.Sprintf("Error verifying namespace '%s': %v", cfg.Namespace, err))
+		return fmt.Errorf("error verifying namespace '%s': %v", cfg.Namespace, err)
+	}
+
+	if err := AssignNamespaceToProject(cfg, clusterID, cfg.Namespace, projectID); err != nil {
+		logger.Error(fmt.Sprintf("Error assigning namespace '%s' to project '%s': %v", cfg.Namespace, cfg.ProjectName, err))
+		return fmt.Errorf("error assigning namespace '%s' to project '%s': %v", cfg.Namespace, cfg.ProjectName, err)
+	}
+
+	if err := VerifyProjectAssignment(cfg, clusterID, cfg.Namespace, projectID); err != nil {
+		logger.Error(fmt.Sprintf("Error verifying project assignment for namespace '%s': %v", cfg.Namespace, err))
+		return fmt.Errorf("error verifying project assignment for namespace '%s': %v", cfg.Namespace, err)
+	}
+
+	if created && cfg.CreateNetworkPolicies {
+		if err := CreateDefaultNetworkPolicies(cfg, clusterID, cfg.Namespace, projectID); err != nil {
+			logger.Error(fmt.Sprintf("Error creating network policies in namespace '%s': %v", cfg.Namespace, err))
+			return fmt.Errorf("error creating network policies in namespace '%s': %v", cfg.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// KubeconfigPath returns where the kubeconfig for a cluster is written: the configured kubeconfig
+// file, or <kubeconfig-dir>/<kubeconfig-prefix><cluster name> when no file is set.
+func KubeconfigPath(cfg *config.Config, clusterName string) string {
+	if cfg.KubeconfigFile != "" {
+		return cfg.KubeconfigFile
+	}
+	return filepath.Join(cfg.KubeconfigDir, cfg.KubeconfigPrefix+clusterName)
+}
@@ -2,49 +2,37 @@ package rancher
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/notify"
 )
 
-// MultiCluster processes multiple clusters based on configuration settings.
+// MultiCluster processes every active cluster matching the configured cluster type or labels.
 func MultiCluster(cfg *config.Config) error {
-	logger.Info("Fetching all cluster IDs...")
+	logger.Info("Selecting clusters...")
 
-	if err := GetAllClusterIDs(cfg); err != nil {
-		logger.Error(fmt.Sprintf("Failed to get all cluster IDs: %v", err))
-		return fmt.Errorf("failed to get all cluster IDs: %v", err)
+	clusters, err := SelectClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to select clusters: %v", err))
+		return fmt.Errorf("failed to select clusters: %v", err)
 	}
 
-	keyPairs := strings.Split(cfg.ClusterLabels, ",")
-	logger.Debug(fmt.Sprintf("Parsed cluster labels: %v", keyPairs))
+	var failed []string
+	for _, cluster := range clusters {
+		logger.Info(fmt.Sprintf("Processing cluster: %s", cluster.Name))
 
-	for _, clusterID := range cfg.ClusterIDs {
-		clusterName, _, err := ParseClusterID(clusterID)
-		if err != nil {
-			logger.Error(fmt.Sprintf("Error parsing cluster ID '%s': %v", clusterID, err))
-			continue // Skip this cluster and move to the next.
-		}
-
-		logger.Info(fmt.Sprintf("Checking if cluster %s is active...", clusterName))
-		active, err := IsClusterActive(clusterName, cfg)
-		if err != nil {
-			logger.Error(fmt.Sprintf("Failed to check if cluster %s is active: %v", clusterName, err))
+		clusterCfg := *cfg
+		clusterCfg.ClusterName = cluster.Name
+		if err := MainProject(&clusterCfg, cluster.ID); err != nil {
+			logger.Error(fmt.Sprintf("Failed to process cluster %s: %v", cluster.Name, err))
+			failed = append(failed, cluster.Name)
+			Notify(cfg, notify.Event{Type: notify.ClusterFailed, Cluster: cluster.Name, ClusterID: cluster.ID, Error: err.Error()})
 			continue // Skip this cluster and move to the next.
 		}
+	}
 
-		if active {
-			logger.Info(fmt.Sprintf("Processing active cluster: %s", clusterName))
-			if cfg.ClusterType != "" {
-				logger.Info(fmt.Sprintf("Processing cluster %s by type...", clusterName))
-				ClusterByType(clusterName, cfg)
-			} else if cfg.ClusterLabels != "" {
-				logger.Info(fmt.Sprintf("Processing cluster %s by labels...", clusterName))
-				ClusterByLabels(clusterName, keyPairs, cfg)
-			}
-		} else {
-			logger.Warn(fmt.Sprintf("Skipping cluster %s because it is not active", clusterName))
-		}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to process %d of %d clusters: %v", len(failed), len(clusters), failed)
 	}
 	return nil
 }
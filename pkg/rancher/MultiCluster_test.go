@@ -0,0 +1,62 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestGetAllClusterIDs(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	other := srv.AddCluster(ranchertest.Cluster{Name: "other", Provider: "k3s"})
+
+	require.NoError(t, GetAllClusterIDs(cfg))
+	assert.Equal(t, []string{"downstream:" + cluster.ID, "other:" + other.ID}, cfg.ClusterIDs)
+}
+
+func TestClusterLookups(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	srv.AddCluster(ranchertest.Cluster{Name: "broken", State: "unavailable", Labels: map[string]string{"env": "prod"}})
+
+	clusterID, err := GetClusterID(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, cluster.ID, clusterID)
+
+	clusterType, err := GetClusterType(cfg, "downstream")
+	require.NoError(t, err)
+	assert.Equal(t, "rke2", clusterType)
+
+	status, err := GetClusterStatus(cfg, "broken")
+	require.NoError(t, err)
+	assert.Equal(t, "unavailable", status)
+
+	active, err := IsClusterActive("downstream", cfg)
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	active, err = IsClusterActive("broken", cfg)
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	matches, err := FilterByClusterLabels("broken", []string{"env=prod"}, cfg)
+	require.NoError(t, err)
+	assert.True(t, matches)
+
+	matches, err = FilterByClusterLabels("downstream", []string{"env=prod"}, cfg)
+	require.NoError(t, err)
+	assert.False(t, matches)
+}
+
+func TestVerifyAccessRejectsBadCredentials(t *testing.T) {
+	_, cfg, _ := newTestServer(t)
+	require.NoError(t, VerifyAccess(cfg))
+
+	cfg.RancherSecretKey = "wrong"
+	assert.ErrorContains(t, VerifyAccess(cfg), "status code: 401")
+
+	cfg.RancherToken = ranchertest.AccessKey + ":" + ranchertest.SecretKey
+	assert.NoError(t, VerifyAccess(cfg))
+}
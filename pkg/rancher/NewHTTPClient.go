@@ -0,0 +1,80 @@
+package rancher
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/metrics"
+)
+
+// NewHTTPClient returns an HTTP client for talking to the Rancher API using the TLS settings from the configuration.
+// Traffic is recorded to or replayed from a cassette when --record or --replay is set.
+func NewHTTPClient(cfg *config.Config) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	roundTripper, err := cassetteTransport(cfg, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: metrics.Transport(roundTripper),
+		Timeout:   time.Second * 10, // 10 seconds timeout
+	}, nil
+}
+
+// newTLSConfig builds the TLS configuration from the CA bundle, client certificate and verification settings.
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipTLSVerify, //nolint:gosec // explicitly requested by the operator
+	}
+	if cfg.InsecureSkipTLSVerify {
+		logger.Debug("TLS certificate verification is disabled")
+	}
+
+	caData := []byte(cfg.CAData)
+	if cfg.CAFile != "" {
+		logger.Debug(fmt.Sprintf("Loading CA bundle from %s", cfg.CAFile))
+		data, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to read CA file: %v", err))
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		caData = data
+	}
+	if len(caData) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caData) {
+			logger.Error("No certificates found in CA bundle")
+			return nil, fmt.Errorf("no certificates found in CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertFile != "" {
+		logger.Debug(fmt.Sprintf("Loading client certificate from %s", cfg.ClientCertFile))
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to load client certificate: %v", err))
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
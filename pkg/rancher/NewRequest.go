@@ -0,0 +1,26 @@
+package rancher
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// NewRequest creates a request to the Rancher API with the JSON content type and the configured credentials.
+// A bearer token takes precedence over the access/secret key pair.
+func NewRequest(cfg *config.Config, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.RancherToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cfg.RancherToken))
+	} else {
+		req.SetBasicAuth(cfg.RancherAccessKey, cfg.RancherSecretKey)
+	}
+	return req, nil
+}
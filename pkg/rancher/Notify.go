@@ -0,0 +1,18 @@
+package rancher
+
+import (
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/notify"
+)
+
+// Notify sends the event to the configured webhooks. Nothing is sent while replaying a
+// cassette, since no change is actually made.
+func Notify(cfg *config.Config, e notify.Event) {
+	if len(cfg.Webhooks) == 0 || cfg.ReplayFile != "" {
+		return
+	}
+	if e.Cluster == "" && e.ClusterID != "" && cfg.ClusterName != "" {
+		e.Cluster = cfg.ClusterName
+	}
+	notify.Send(cfg.Webhooks, e)
+}
@@ -0,0 +1,84 @@
+package rancher
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/supporttools/rancher-projects/pkg/cassette"
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+var (
+	cassettesMu sync.Mutex
+	cassettes   = map[string]*cassette.Cassette{}
+)
+
+// OpenCassette prepares the cassette configured with --record or --replay. Recording starts
+// a new cassette file; replaying loads it and takes the Rancher server URL from it when none
+// is configured. Clients created afterwards record to or replay from the same cassette.
+func OpenCassette(cfg *config.Config) (*cassette.Cassette, error) {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+
+	path, replay := cfg.RecordFile, cfg.ReplayFile != ""
+	if replay {
+		path = cfg.ReplayFile
+	}
+	if path == "" {
+		return nil, nil
+	}
+
+	key := fmt.Sprintf("%t:%s", replay, path)
+	c, ok := cassettes[key]
+	switch {
+	case ok:
+		// Already opened by an earlier client.
+	case replay:
+		logger.Info(fmt.Sprintf("Replaying Rancher API traffic from %s", path))
+		loaded, err := cassette.Load(path)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to load cassette: %v", err))
+			return nil, err
+		}
+		c = loaded
+	default:
+		logger.Info(fmt.Sprintf("Recording Rancher API traffic to %s", path))
+		created, err := cassette.Create(path, cfg.RancherServerURL)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to create cassette: %v", err))
+			return nil, err
+		}
+		c = created
+	}
+	cassettes[key] = c
+
+	if replay && cfg.RancherServerURL == "" {
+		cfg.RancherServerURL = c.Server
+	}
+	return c, nil
+}
+
+// cassetteTransport wraps base so that traffic is recorded to or replayed from the configured
+// cassette. base is returned unchanged when neither --record nor --replay is set.
+func cassetteTransport(cfg *config.Config, base http.RoundTripper) (http.RoundTripper, error) {
+	c, err := OpenCassette(cfg)
+	if err != nil || c == nil {
+		return base, err
+	}
+
+	scrubber := cassette.NewScrubber(cfg.RancherSecretKey, cfg.RancherToken, tokenSecret(cfg.RancherToken))
+	if cfg.ReplayFile != "" {
+		return c.Replayer(scrubber), nil
+	}
+	return c.Recorder(base, scrubber), nil
+}
+
+// tokenSecret returns the secret part of a token-xxxxx:secret bearer token.
+func tokenSecret(token string) string {
+	if i := strings.Index(token, ":"); i >= 0 {
+		return token[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package rancher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	dir := t.TempDir()
+	cfg.RecordFile = filepath.Join(dir, "cassette.jsonl")
+	cfg.ProjectName = "ClusterServices"
+	cfg.Namespace = "monitoring"
+	cfg.CreateProject = true
+	cfg.CreateNamespace = true
+	cfg.CreateKubeconfig = true
+	cfg.KubeconfigFile = filepath.Join(dir, "recorded-kubeconfig")
+
+	require.NoError(t, VerifyAccess(cfg))
+	require.NoError(t, SingleCluster(cfg))
+	recorded := len(srv.Requests())
+
+	data, err := os.ReadFile(cfg.RecordFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), ranchertest.SecretKey)
+	assert.NotContains(t, string(data), "kubeconfig-user-fake")
+	assert.Contains(t, string(data), cluster.ID)
+
+	srv.Close()
+
+	replay := &config.Config{
+		ReplayFile:       cfg.RecordFile,
+		ClusterName:      cfg.ClusterName,
+		ProjectName:      cfg.ProjectName,
+		Namespace:        cfg.Namespace,
+		CreateProject:    true,
+		CreateNamespace:  true,
+		CreateKubeconfig: true,
+		KubeconfigFile:   filepath.Join(dir, "replayed-kubeconfig"),
+	}
+	require.NoError(t, replay.Validate())
+	c, err := OpenCassette(replay)
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL, replay.RancherServerURL)
+	assert.Len(t, c.Interactions, recorded)
+
+	require.NoError(t, VerifyAccess(replay))
+	require.NoError(t, SingleCluster(replay))
+	assert.Zero(t, c.Unused())
+
+	kubeconfig, err := os.ReadFile(replay.KubeconfigFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(kubeconfig), `token: "REDACTED"`)
+}
+
+func TestReplayUnknownRequest(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	cfg.RecordFile = filepath.Join(t.TempDir(), "cassette.jsonl")
+	require.NoError(t, VerifyAccess(cfg))
+	srv.Close()
+
+	replay := &config.Config{ReplayFile: cfg.RecordFile, ClusterName: "downstream"}
+	_, err := GetClusterID(replay)
+	assert.ErrorContains(t, err, "no recorded interaction for GET /v3/clusters?name=downstream")
+}
@@ -0,0 +1,14 @@
+package rancher
+
+import (
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// ProjectAPI returns the API used to manage projects, norman or steve: the one set with
+// --project-api, or otherwise the one the server's capabilities list.
+func ProjectAPI(cfg *config.Config) string {
+	if cfg.ProjectAPI == config.APINorman || cfg.ProjectAPI == config.APISteve {
+		return cfg.ProjectAPI
+	}
+	return ServerCapabilities(cfg).ProjectAPI
+}
@@ -0,0 +1,101 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestProjectAPIByServerVersion(t *testing.T) {
+	tests := []struct {
+		serverVersion string
+		want          string
+	}{
+		{"", config.APINorman},
+		{"v2.7.10", config.APINorman},
+		{"v2.8.0", config.APISteve},
+		{"v2.9.2", config.APISteve},
+		{"dev", config.APINorman},
+	}
+	for _, tt := range tests {
+		srv, cfg, _ := newTestServer(t)
+		if tt.serverVersion != "" {
+			srv.SetSetting("server-version", tt.serverVersion)
+		}
+		assert.Equal(t, tt.want, ProjectAPI(cfg), tt.serverVersion)
+
+		// The choice is made once.
+		assert.Equal(t, tt.want, ProjectAPI(cfg), tt.serverVersion)
+		assert.Equal(t, 1, srv.CountRequests("GET", "/v3/settings/server-version"))
+	}
+
+	srv, cfg, _ := newTestServer(t)
+	srv.SetSetting("server-version", "v2.9.2")
+	cfg.ProjectAPI = config.APINorman
+	assert.Equal(t, config.APINorman, ProjectAPI(cfg))
+	assert.Zero(t, srv.CountRequests("GET", "/v3/settings/"))
+}
+
+func TestSteveProjects(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	cfg.ProjectAPI = config.APISteve
+	cfg.ProjectName = "ClusterServices"
+	cfg.Namespace = "monitoring"
+	cfg.CreateProject = true
+	cfg.CreateNamespace = true
+
+	require.NoError(t, SingleCluster(cfg))
+	require.NoError(t, SingleCluster(cfg))
+
+	project, ok := srv.Project(cluster.ID, "ClusterServices")
+	require.True(t, ok, "project should have been created")
+	ns, ok := srv.Namespace(cluster.ID, "monitoring")
+	require.True(t, ok, "namespace should have been created")
+	assert.Equal(t, project.ID, ns.ProjectID())
+	assert.Equal(t, 1, srv.CountRequests("POST", "/v1/management.cattle.io.projects"))
+	assert.Zero(t, srv.CountRequests("", "/v3/projects"))
+
+	projectID, err := GetProjectInfo(cfg, cluster.ID, "ClusterServices")
+	require.NoError(t, err)
+	assert.Equal(t, project.ID, projectID)
+	_, err = GetProjectInfo(cfg, cluster.ID, "Missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, VerifyProject(cfg, cluster.ID, "Missing"), "project Missing not found")
+
+	srv.UpdateProject(cluster.ID, "ClusterServices", func(p *ranchertest.Project) {
+		p.Labels = map[string]string{"cattle.io/creator": "norman"}
+		p.Annotations = map[string]string{"owner": "platform", "obsolete": "yes"}
+	})
+	require.NoError(t, UpdateProject(cfg, cluster.ID, project.ID, map[string]interface{}{
+		"description":   "Shared services",
+		"labels":        map[string]string{"team": "platform"},
+		"annotations":   map[string]interface{}{"obsolete": nil},
+		"resourceQuota": map[string]interface{}{"limit": map[string]interface{}{"limitsCpu": "4000m"}},
+	}))
+
+	got, err := GetProject(cfg, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ClusterServices", got.Name)
+	assert.Equal(t, cluster.ID, got.ClusterId)
+	assert.Equal(t, "Shared services", got.Description)
+	assert.Equal(t, map[string]string{"cattle.io/creator": "norman", "team": "platform"}, got.Labels, "labels are merged")
+	assert.Equal(t, map[string]string{"owner": "platform"}, got.Annotations, "only annotations set to nil are removed")
+	assert.Equal(t, map[string]interface{}{"limit": map[string]interface{}{"limitsCpu": "4000m"}}, got.ResourceQuota)
+
+	puts := srv.CountRequests("PUT", "")
+	err = UpdateProject(cfg, cluster.ID, project.ID, map[string]interface{}{"descripton": "typo"})
+	assert.ErrorContains(t, err, `project field "descripton" is not supported`)
+	assert.Equal(t, puts, srv.CountRequests("PUT", ""), "nothing is sent for an unsupported field")
+
+	projects, err := ListProjects(cfg, cluster.ID)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, project.ID, projects[0].Id)
+
+	_, err = GetProject(cfg, cluster.ID+":p-missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
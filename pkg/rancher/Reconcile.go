@@ -0,0 +1,178 @@
+package rancher
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/manifest"
+	"github.com/supporttools/rancher-projects/pkg/metrics"
+	"github.com/supporttools/rancher-projects/pkg/notify"
+)
+
+// ReconcileSummary counts what a reconcile cycle found and changed.
+type ReconcileSummary struct {
+	Clusters             int           `json:"clusters"`
+	ProjectsCreated      int           `json:"projectsCreated"`
+	NamespacesCreated    int           `json:"namespacesCreated"`
+	NamespacesAssigned   int           `json:"namespacesAssigned"`
+	UnexpectedNamespaces int           `json:"unexpectedNamespaces"`
+	Errors               []string      `json:"errors,omitempty"`
+	Duration             time.Duration `json:"duration"`
+}
+
+// String formats the summary as a single log line.
+func (s *ReconcileSummary) String() string {
+	return fmt.Sprintf("clusters=%d projectsCreated=%d namespacesCreated=%d namespacesAssigned=%d unexpectedNamespaces=%d errors=%d duration=%s",
+		s.Clusters, s.ProjectsCreated, s.NamespacesCreated, s.NamespacesAssigned, s.UnexpectedNamespaces, len(s.Errors), s.Duration.Round(time.Millisecond))
+}
+
+// Reconcile applies the desired state to every selected cluster: missing projects and namespaces
+// are created and namespaces assigned to the wrong project are moved. Unexpected namespaces are
+// only counted. Clusters are selected anew on every call, so newly registered clusters that match
+// the selection are picked up. Failures in one cluster are recorded and do not stop the others.
+func Reconcile(cfg *config.Config, desired *manifest.Manifest) (*ReconcileSummary, error) {
+	start := time.Now()
+	summary := &ReconcileSummary{}
+
+	clusters, err := SelectClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to select clusters: %v", err))
+		recordCycle("error", time.Since(start))
+		return nil, fmt.Errorf("failed to select clusters: %v", err)
+	}
+
+	for _, cluster := range clusters {
+		summary.Clusters++
+		if err := ReconcileCluster(cfg, cluster, desired, summary); err != nil {
+			logger.Error(fmt.Sprintf("Failed to reconcile cluster %s: %v", cluster.Name, err))
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", cluster.Name, err))
+			Notify(cfg, notify.Event{Type: notify.ClusterFailed, Cluster: cluster.Name, ClusterID: cluster.ID, Error: err.Error()})
+		}
+	}
+
+	summary.Duration = time.Since(start)
+	result := "success"
+	if len(summary.Errors) > 0 {
+		result = "error"
+	}
+	recordCycle(result, summary.Duration)
+	metrics.ReconciledClusters.Set(float64(summary.Clusters))
+	return summary, nil
+}
+
+// recordCycle updates the reconcile cycle metrics.
+func recordCycle(result string, duration time.Duration) {
+	metrics.ReconcileCycles.WithLabelValues(result).Inc()
+	metrics.ReconcileDuration.Observe(duration.Seconds())
+	metrics.LastReconcile.WithLabelValues(result).SetToCurrentTime()
+}
+
+// ReconcileCluster applies the desired state to one cluster and adds the changes to summary.
+// Projects get the Pod Security level of the manifest; namespaces already in a project keep
+// their labels, which the check command reports.
+func ReconcileCluster(cfg *config.Config, cluster ClusterRef, desired *manifest.Manifest, summary *ReconcileSummary) error {
+	logger.Info(fmt.Sprintf("Reconciling cluster %s (%s)...", cluster.Name, cluster.ID))
+
+	projects, err := ListProjects(cfg, cluster.ID)
+	if err != nil {
+		return err
+	}
+	namespaces, err := ListNamespaces(cfg, cluster.ID)
+	if err != nil {
+		return err
+	}
+
+	projectIDs := map[string]string{}
+	for _, p := range projects {
+		projectIDs[p.Name] = p.Id
+	}
+	projectID := func(name string) (string, error) {
+		if id, ok := projectIDs[name]; ok {
+			return id, nil
+		}
+		id, err := GetProjectInfo(cfg, cluster.ID, name)
+		if err != nil {
+			return "", err
+		}
+		projectIDs[name] = id
+		return id, nil
+	}
+
+	// Levels are set before namespaces are assigned, so assigned namespaces get their labels.
+	setPodSecurity := func(name string) error {
+		p, _ := desired.Project(name)
+		if p.PodSecurity == "" {
+			return nil
+		}
+		id, err := projectID(name)
+		if err != nil {
+			return err
+		}
+		return SetProjectPodSecurity(cfg, cluster.ID, id, p.PodSecurity)
+	}
+	for _, p := range desired.Projects {
+		if _, exists := projectIDs[p.Name]; exists {
+			if err := setPodSecurity(p.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	findings := CompareCluster(desired, projects, namespaces)
+	recordDrift(cluster.ID, findings)
+	for _, finding := range findings {
+		created := false
+		switch finding.Kind {
+		case DriftMissingProject:
+			if err := CreateProject(cfg, cluster.ID, finding.Project); err != nil {
+				return err
+			}
+			summary.ProjectsCreated++
+			if err := setPodSecurity(finding.Project); err != nil {
+				return err
+			}
+
+		case DriftMissingNamespace:
+			if created, err = createNamespace(cfg, cluster.ID, finding.Namespace); err != nil {
+				return err
+			}
+			if created {
+				summary.NamespacesCreated++
+			}
+			fallthrough
+
+		case DriftWrongProject:
+			id, err := projectID(finding.Project)
+			if err != nil {
+				return err
+			}
+			if err := AssignNamespaceToProject(cfg, cluster.ID, finding.Namespace, id); err != nil {
+				return err
+			}
+			summary.NamespacesAssigned++
+			if created && cfg.CreateNetworkPolicies {
+				if err := CreateDefaultNetworkPolicies(cfg, cluster.ID, finding.Namespace, id); err != nil {
+					return err
+				}
+			}
+
+		case DriftUnexpectedNamespace:
+			logger.Warn(fmt.Sprintf("Namespace %s in cluster %s is assigned to project %s but not listed in the manifest", finding.Namespace, cluster.Name, finding.Project))
+			summary.UnexpectedNamespaces++
+		}
+	}
+
+	if cfg.NetworkIsolation {
+		for _, p := range desired.Projects {
+			id, err := projectID(p.Name)
+			if err != nil {
+				return err
+			}
+			if err := EnableProjectNetworkIsolation(cfg, cluster.ID, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
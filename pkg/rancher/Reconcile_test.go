@@ -0,0 +1,156 @@
+package rancher
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/manifest"
+	"github.com/supporttools/rancher-projects/pkg/metrics"
+	"github.com/supporttools/rancher-projects/pkg/notify"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestReconcile(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	cfg.ClusterName = ""
+	cfg.ClusterLabels = "env=prod"
+
+	prod := srv.AddCluster(ranchertest.Cluster{Name: "prod", Labels: map[string]string{"env": "prod"}})
+	defaultProject := srv.AddProject(prod.ID, "Default")
+	srv.AddNamespace(prod.ID, "monitoring", defaultProject.ID)
+
+	desired := &manifest.Manifest{Projects: []manifest.Project{
+		{Name: "ClusterServices", Namespaces: []string{"monitoring", "logging"}},
+	}}
+	cycles := testutil.ToFloat64(metrics.ReconcileCycles.WithLabelValues("success"))
+
+	summary, err := Reconcile(cfg, desired)
+	require.NoError(t, err)
+	assert.Equal(t, cycles+1, testutil.ToFloat64(metrics.ReconcileCycles.WithLabelValues("success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ProjectsCreated.WithLabelValues(prod.ID)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.NamespacesCreated.WithLabelValues(prod.ID)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.NamespacesAssigned.WithLabelValues(prod.ID)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.DriftFindings.WithLabelValues(prod.ID, DriftWrongProject)))
+	assert.Equal(t, 1, summary.Clusters)
+	assert.Equal(t, 1, summary.ProjectsCreated)
+	assert.Equal(t, 1, summary.NamespacesCreated)
+	assert.Equal(t, 2, summary.NamespacesAssigned)
+	assert.Empty(t, summary.Errors)
+
+	project, ok := srv.Project(prod.ID, "ClusterServices")
+	require.True(t, ok)
+	for _, name := range []string{"monitoring", "logging"} {
+		ns, ok := srv.Namespace(prod.ID, name)
+		require.True(t, ok)
+		assert.Equal(t, project.ID, ns.ProjectID(), name)
+	}
+
+	// A cluster registered later is picked up by the next cycle; the first one is left alone.
+	staging := srv.AddCluster(ranchertest.Cluster{Name: "prod-2", Labels: map[string]string{"env": "prod"}})
+	summary, err = Reconcile(cfg, desired)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Clusters)
+	assert.Equal(t, 1, summary.ProjectsCreated)
+	assert.Equal(t, 2, summary.NamespacesCreated)
+	_, ok = srv.Project(staging.ID, "ClusterServices")
+	assert.True(t, ok)
+	assert.Contains(t, summary.String(), "clusters=2 projectsCreated=1 namespacesCreated=2 namespacesAssigned=2")
+}
+
+func TestReconcileNamespaceCreatedConcurrently(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	srv.AddProject(cluster.ID, "ClusterServices")
+	desired := &manifest.Manifest{Projects: []manifest.Project{
+		{Name: "ClusterServices", Namespaces: []string{"logging"}},
+	}}
+	// Someone else creates the namespace after the drift check has found it missing.
+	srv.OnRequest("POST", "/k8s/clusters/"+cluster.ID+"/v1/namespaces", func() {
+		if _, ok := srv.Namespace(cluster.ID, "logging"); !ok {
+			srv.AddNamespace(cluster.ID, "logging", "")
+		}
+	})
+
+	summary, err := Reconcile(cfg, desired)
+	require.NoError(t, err)
+	assert.Zero(t, summary.NamespacesCreated, "the namespace was not created by this cycle")
+	assert.Equal(t, 1, summary.NamespacesAssigned)
+
+	project, _ := srv.Project(cluster.ID, "ClusterServices")
+	ns, ok := srv.Namespace(cluster.ID, "logging")
+	require.True(t, ok)
+	assert.Equal(t, project.ID, ns.ProjectID())
+}
+
+func TestReconcileRecordsClusterErrors(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	srv.Fail("POST", "/v3/projects", 403)
+
+	summary, err := Reconcile(cfg, &manifest.Manifest{Projects: []manifest.Project{{Name: "ClusterServices"}}})
+	require.NoError(t, err)
+	require.Len(t, summary.Errors, 1)
+	assert.Contains(t, summary.Errors[0], cluster.Name)
+}
+
+func TestMultiCluster(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	cfg.ClusterName = ""
+	cfg.ClusterType = "k3s"
+	cfg.ProjectName = "Edge"
+	cfg.CreateProject = true
+
+	edge := srv.AddCluster(ranchertest.Cluster{Name: "edge", Provider: "k3s"})
+
+	require.NoError(t, MultiCluster(cfg))
+	_, ok := srv.Project(edge.ID, "Edge")
+	assert.True(t, ok)
+	assert.Len(t, srv.Projects(srv.Clusters()[0].ID), 0, "clusters of another type are not touched")
+}
+
+func TestReconcileSendsNotifications(t *testing.T) {
+	var mu sync.Mutex
+	var events []notify.Event
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e notify.Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err == nil {
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		}
+	}))
+	defer receiver.Close()
+
+	srv, cfg, cluster := newTestServer(t)
+	cfg.Webhooks = []config.Webhook{{URL: receiver.URL}}
+	desired := &manifest.Manifest{Projects: []manifest.Project{{Name: "Team", Namespaces: []string{"team"}}}}
+
+	_, err := Reconcile(cfg, desired)
+	require.NoError(t, err)
+	var types []string
+	for _, e := range events {
+		assert.Equal(t, cluster.ID, e.ClusterID)
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []string{notify.ProjectCreated, notify.NamespaceCreated, notify.NamespaceAssigned}, types)
+
+	// Failures are reported per cluster, and nothing is sent while replaying.
+	events = nil
+	srv.Fail("GET", "/v3/projects", http.StatusInternalServerError)
+	_, err = Reconcile(cfg, desired)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, notify.ClusterFailed, events[0].Type)
+	assert.Equal(t, cluster.Name, events[0].Cluster)
+
+	events = nil
+	cfg.ReplayFile = "cassette.json"
+	Notify(cfg, notify.Event{Type: notify.ProjectCreated})
+	assert.Empty(t, events)
+}
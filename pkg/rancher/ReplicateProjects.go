@@ -0,0 +1,277 @@
+package rancher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// ReplicationSummary counts what ReplicateProjects changed.
+type ReplicationSummary struct {
+	Source             string        `json:"source"`
+	Clusters           int           `json:"clusters"`
+	ProjectsCreated    int           `json:"projectsCreated"`
+	ProjectsUpdated    int           `json:"projectsUpdated"`
+	MembersAdded       int           `json:"membersAdded"`
+	NamespacesCreated  int           `json:"namespacesCreated"`
+	NamespacesAssigned int           `json:"namespacesAssigned"`
+	Errors             []string      `json:"errors,omitempty"`
+	Duration           time.Duration `json:"duration"`
+}
+
+// String formats the summary as a single log line.
+func (s *ReplicationSummary) String() string {
+	return fmt.Sprintf("source=%s clusters=%d projectsCreated=%d projectsUpdated=%d membersAdded=%d namespacesCreated=%d namespacesAssigned=%d errors=%d duration=%s",
+		s.Source, s.Clusters, s.ProjectsCreated, s.ProjectsUpdated, s.MembersAdded, s.NamespacesCreated, s.NamespacesAssigned, len(s.Errors), s.Duration.Round(time.Millisecond))
+}
+
+// projectSource is a project of the source cluster with its members.
+type projectSource struct {
+	Project
+	Members []ProjectMember
+}
+
+// ReplicateProjects makes the projects of every selected cluster match those of the source cluster:
+// projects are created by name, their description, labels, resource quotas and default limits are
+// copied, missing member bindings are added and namespaces are assigned to the same projects.
+// Project IDs are translated by project name. Namespaces missing on a target are created only with
+// --create-namespace. Nothing is removed from the targets. Failures in one cluster are recorded and
+// do not stop the others.
+func ReplicateProjects(cfg *config.Config, source string) (*ReplicationSummary, error) {
+	start := time.Now()
+	summary := &ReplicationSummary{Source: source}
+
+	sourceCfg := *cfg
+	sourceCfg.ClusterName = source
+	sourceID, err := GetClusterID(&sourceCfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to find source cluster %s: %v", source, err))
+		return nil, fmt.Errorf("failed to find source cluster %s: %w", source, err)
+	}
+
+	projects, err := ListProjects(&sourceCfg, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source cluster %s: %v", source, err)
+	}
+	namespaces, err := ListNamespaces(&sourceCfg, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source cluster %s: %v", source, err)
+	}
+	sources := make([]projectSource, 0, len(projects))
+	for _, p := range projects {
+		members, err := ListProjectMembers(&sourceCfg, p.Id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source cluster %s: %v", source, err)
+		}
+		sources = append(sources, projectSource{Project: p, Members: members})
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Name < sources[j].Name })
+
+	// Namespace assignments by project name, so they can be translated to the target's project IDs.
+	assignments := map[string]string{}
+	for _, ns := range namespaces {
+		for _, p := range projects {
+			if p.Id == ns.ProjectID() {
+				assignments[ns.Name] = p.Name
+			}
+		}
+	}
+
+	targets, err := SelectClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to select clusters: %v", err))
+		return nil, fmt.Errorf("failed to select clusters: %v", err)
+	}
+	for _, target := range targets {
+		if target.ID == sourceID {
+			continue
+		}
+		summary.Clusters++
+		targetCfg := *cfg
+		targetCfg.ClusterName = target.Name
+		if err := replicateCluster(&targetCfg, target, sources, assignments, summary); err != nil {
+			logger.Error(fmt.Sprintf("Failed to replicate projects to cluster %s: %v", target.Name, err))
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", target.Name, err))
+		}
+	}
+
+	summary.Duration = time.Since(start)
+	return summary, nil
+}
+
+// replicateCluster applies the source projects and namespace assignments to one target cluster.
+func replicateCluster(cfg *config.Config, target ClusterRef, sources []projectSource, assignments map[string]string, summary *ReplicationSummary) error {
+	logger.Info(fmt.Sprintf("Replicating %d project(s) to cluster %s (%s)...", len(sources), target.Name, target.ID))
+
+	existing, err := ListProjects(cfg, target.ID)
+	if err != nil {
+		return err
+	}
+	byName := map[string]Project{}
+	for _, p := range existing {
+		byName[p.Name] = p
+	}
+
+	projectIDs := map[string]string{}
+	for _, src := range sources {
+		p, ok := byName[src.Name]
+		if !ok {
+			if err := CreateProject(cfg, target.ID, src.Name); err != nil {
+				return err
+			}
+			summary.ProjectsCreated++
+			id, err := GetProjectInfo(cfg, target.ID, src.Name)
+			if err != nil {
+				return err
+			}
+			p = Project{Id: id, Name: src.Name}
+		}
+		projectIDs[src.Name] = p.Id
+
+		if changes := projectChanges(src.Project, p); len(changes) > 0 {
+			if err := UpdateProject(cfg, target.ID, p.Id, changes); err != nil {
+				return err
+			}
+			summary.ProjectsUpdated++
+		}
+
+		added, err := replicateMembers(cfg, target.ID, p.Id, src.Members)
+		summary.MembersAdded += added
+		if err != nil {
+			return err
+		}
+	}
+
+	namespaces, err := ListNamespaces(cfg, target.ID)
+	if err != nil {
+		return err
+	}
+	current := map[string]string{}
+	for _, ns := range namespaces {
+		current[ns.Name] = ns.ProjectID()
+	}
+
+	names := make([]string, 0, len(assignments))
+	for name := range assignments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		projectID := projectIDs[assignments[name]]
+		have, exists := current[name]
+		created := false
+		if !exists {
+			if !cfg.CreateNamespace {
+				logger.Info(fmt.Sprintf("Namespace %s does not exist in cluster %s, skipping", name, target.Name))
+				continue
+			}
+			if created, err = createNamespace(cfg, target.ID, name); err != nil {
+				return err
+			}
+			summary.NamespacesCreated++
+		}
+		if have == projectID {
+			continue
+		}
+		if err := AssignNamespaceToProject(cfg, target.ID, name, projectID); err != nil {
+			return err
+		}
+		summary.NamespacesAssigned++
+		if created && cfg.CreateNetworkPolicies {
+			if err := CreateDefaultNetworkPolicies(cfg, target.ID, name, projectID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// projectChanges returns the fields of the target project that differ from the source. Labels
+// are merged into the target's labels; labels set by Rancher itself are not copied.
+func projectChanges(source, target Project) map[string]interface{} {
+	changes := map[string]interface{}{}
+	if source.Description != target.Description {
+		changes["description"] = source.Description
+	}
+
+	labels := map[string]string{}
+	for k, v := range target.Labels {
+		labels[k] = v
+	}
+	changed := false
+	for k, v := range source.Labels {
+		if isRancherKey(k) || labels[k] == v {
+			continue
+		}
+		labels[k] = v
+		changed = true
+	}
+	if changed {
+		changes["labels"] = labels
+	}
+
+	for field, values := range map[string][2]map[string]interface{}{
+		"resourceQuota":                 {source.ResourceQuota, target.ResourceQuota},
+		"namespaceDefaultResourceQuota": {source.NamespaceDefaultResourceQuota, target.NamespaceDefaultResourceQuota},
+		"containerDefaultResourceLimit": {source.ContainerDefaultResourceLimit, target.ContainerDefaultResourceLimit},
+	} {
+		if len(values[0]) > 0 && !sameJSON(values[0], values[1]) {
+			changes[field] = values[0]
+		}
+	}
+	return changes
+}
+
+// replicateMembers adds the source bindings missing from the target project and returns how many were added.
+func replicateMembers(cfg *config.Config, clusterID, projectID string, members []ProjectMember) (int, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	existing, err := ListProjectMembers(cfg, projectID)
+	if err != nil {
+		return 0, err
+	}
+	have := map[ProjectMember]bool{}
+	for _, m := range existing {
+		have[memberKey(m)] = true
+	}
+
+	added := 0
+	for _, m := range members {
+		m.ProjectID = projectID
+		if have[memberKey(m)] {
+			continue
+		}
+		if err := AddProjectMember(cfg, clusterID, m); err != nil {
+			return added, err
+		}
+		have[memberKey(m)] = true
+		added++
+	}
+	return added, nil
+}
+
+// memberKey identifies a binding by project, role and principal, ignoring its ID.
+func memberKey(m ProjectMember) ProjectMember {
+	m.ID = ""
+	return m
+}
+
+// isRancherKey reports whether a label key is managed by Rancher, e.g. cattle.io/creator.
+func isRancherKey(key string) bool {
+	prefix, _, found := strings.Cut(key, "/")
+	return found && (prefix == "cattle.io" || strings.HasSuffix(prefix, ".cattle.io"))
+}
+
+// sameJSON reports whether a and b encode to the same JSON, so quotas decoded from different
+// responses compare equal regardless of number types.
+func sameJSON(a, b interface{}) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
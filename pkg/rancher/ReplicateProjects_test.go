@@ -0,0 +1,85 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestReplicateProjects(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	cfg.ClusterName = ""
+	cfg.ClusterLabels = "env=prod"
+	cfg.CreateNamespace = true
+
+	golden := srv.AddCluster(ranchertest.Cluster{Name: "golden", Labels: map[string]string{"env": "prod"}})
+	target := srv.AddCluster(ranchertest.Cluster{Name: "new", Labels: map[string]string{"env": "prod"}})
+
+	quota := map[string]interface{}{"limit": map[string]interface{}{"pods": "100"}}
+	team := srv.AddProject(golden.ID, "Team")
+	srv.UpdateProject(golden.ID, "Team", func(p *ranchertest.Project) {
+		p.Description = "Team services"
+		p.Labels = map[string]string{"cost-center": "42", "cattle.io/creator": "norman"}
+		p.ResourceQuota = quota
+		p.NamespaceDefaultResourceQuota = map[string]interface{}{"limit": map[string]interface{}{"pods": "10"}}
+	})
+	srv.AddProjectMember(ranchertest.ProjectMember{ProjectID: team.ID, RoleTemplateID: "project-member", GroupPrincipalID: "github_team://1"})
+	srv.AddNamespace(golden.ID, "team", team.ID)
+	srv.AddNamespace(golden.ID, "team-db", team.ID)
+	srv.AddNamespace(golden.ID, "scratch", "")
+
+	// The target already has the project under another ID and one namespace in the wrong place.
+	existing := srv.AddProject(target.ID, "Team")
+	other := srv.AddProject(target.ID, "Other")
+	srv.AddNamespace(target.ID, "team", other.ID)
+
+	summary, err := ReplicateProjects(cfg, golden.Name)
+	require.NoError(t, err)
+	assert.Empty(t, summary.Errors)
+	assert.Equal(t, 1, summary.Clusters, "the source cluster is not a target")
+	assert.Equal(t, 0, summary.ProjectsCreated)
+	assert.Equal(t, 1, summary.ProjectsUpdated)
+	assert.Equal(t, 1, summary.MembersAdded)
+	assert.Equal(t, 1, summary.NamespacesCreated)
+	assert.Equal(t, 2, summary.NamespacesAssigned)
+
+	replicated, ok := srv.Project(target.ID, "Team")
+	require.True(t, ok)
+	assert.Equal(t, existing.ID, replicated.ID)
+	assert.Equal(t, "Team services", replicated.Description)
+	assert.Equal(t, map[string]string{"cost-center": "42"}, replicated.Labels)
+	assert.True(t, sameJSON(quota, replicated.ResourceQuota))
+	assert.Equal(t, []ranchertest.ProjectMember{{
+		ID: srv.ProjectMembers(existing.ID)[0].ID, ProjectID: existing.ID, RoleTemplateID: "project-member", GroupPrincipalID: "github_team://1",
+	}}, srv.ProjectMembers(existing.ID))
+	for _, name := range []string{"team", "team-db"} {
+		ns, ok := srv.Namespace(target.ID, name)
+		require.True(t, ok, name)
+		assert.Equal(t, existing.ID, ns.ProjectID(), name)
+	}
+	_, ok = srv.Namespace(target.ID, "scratch")
+	assert.False(t, ok, "namespaces outside a project are not replicated")
+
+	// A second run finds nothing to do.
+	summary, err = ReplicateProjects(cfg, golden.Name)
+	require.NoError(t, err)
+	assert.Contains(t, summary.String(), "projectsCreated=0 projectsUpdated=0 membersAdded=0 namespacesCreated=0 namespacesAssigned=0 errors=0")
+
+	// New clusters get the project created.
+	fresh := srv.AddCluster(ranchertest.Cluster{Name: "fresh", Labels: map[string]string{"env": "prod"}})
+	summary, err = ReplicateProjects(cfg, golden.Name)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.ProjectsCreated)
+	created, ok := srv.Project(fresh.ID, "Team")
+	require.True(t, ok)
+	assert.Len(t, srv.ProjectMembers(created.ID), 1)
+}
+
+func TestReplicateProjectsUnknownSource(t *testing.T) {
+	_, cfg, _ := newTestServer(t)
+	_, err := ReplicateProjects(cfg, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,143 @@
+package rancher
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// RestoreSummary counts what RestoreSnapshot changed.
+type RestoreSummary struct {
+	Clusters  int           `json:"clusters"`
+	Restored  int           `json:"restored"`
+	Unchanged int           `json:"unchanged"`
+	Skipped   []string      `json:"skipped,omitempty"`
+	Errors    []string      `json:"errors,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// String formats the summary as a single log line.
+func (s *RestoreSummary) String() string {
+	return fmt.Sprintf("clusters=%d restored=%d unchanged=%d skipped=%d errors=%d duration=%s",
+		s.Clusters, s.Restored, s.Unchanged, len(s.Skipped), len(s.Errors), s.Duration.Round(time.Millisecond))
+}
+
+// RestoreSnapshot assigns every namespace whose project changed since the snapshot back to its
+// recorded project. Only clusters that are both in the snapshot and selected by the configuration
+// are restored. A project that was deleted and recreated is found by name. Namespaces that no
+// longer exist, that were not in a project, or whose project is gone are skipped; namespaces are
+// never created or removed from a project.
+//
+// Cluster IDs such as local exist on every Rancher server, so a snapshot taken on another server
+// is refused unless --allow-other-server is set.
+func RestoreSnapshot(cfg *config.Config, snapshot *Snapshot) (*RestoreSummary, error) {
+	start := time.Now()
+	summary := &RestoreSummary{}
+
+	if strings.TrimSuffix(snapshot.Server, "/") != strings.TrimSuffix(cfg.RancherServerURL, "/") {
+		if !cfg.AllowOtherServer {
+			logger.Error(fmt.Sprintf("Snapshot was taken on %s, not on %s", snapshot.Server, cfg.RancherServerURL))
+			return nil, fmt.Errorf("snapshot was taken on Rancher server %s, not on %s: set --allow-other-server to restore it anyway", snapshot.Server, cfg.RancherServerURL)
+		}
+		logger.Warn(fmt.Sprintf("Restoring a snapshot taken on %s to %s", snapshot.Server, cfg.RancherServerURL))
+	}
+
+	clusters, err := SelectClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to select clusters: %v", err))
+		return nil, fmt.Errorf("failed to select clusters: %v", err)
+	}
+	selected := map[string]bool{}
+	for _, c := range clusters {
+		selected[c.ID] = true
+	}
+
+	for _, recorded := range snapshot.Clusters {
+		if !selected[recorded.ClusterID] {
+			logger.Debug(fmt.Sprintf("Skipping cluster %s because it is not selected", recorded.Cluster))
+			continue
+		}
+		summary.Clusters++
+		if err := restoreCluster(cfg, recorded, summary); err != nil {
+			logger.Error(fmt.Sprintf("Failed to restore cluster %s: %v", recorded.Cluster, err))
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", recorded.Cluster, err))
+		}
+	}
+
+	summary.Duration = time.Since(start)
+	return summary, nil
+}
+
+// restoreCluster restores the namespace assignments of one cluster and adds the results to summary.
+func restoreCluster(cfg *config.Config, recorded ClusterSnapshot, summary *RestoreSummary) error {
+	logger.Info(fmt.Sprintf("Restoring namespace assignments in cluster %s (%s)...", recorded.Cluster, recorded.ClusterID))
+
+	clusterCfg := *cfg
+	clusterCfg.ClusterName = recorded.Cluster
+
+	projects, err := ListProjects(&clusterCfg, recorded.ClusterID)
+	if err != nil {
+		return err
+	}
+	namespaces, err := ListNamespaces(&clusterCfg, recorded.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	projectIDs := map[string]bool{}
+	projectsByName := map[string]string{}
+	for _, p := range projects {
+		projectIDs[p.Id] = true
+		projectsByName[p.Name] = p.Id
+	}
+	current := map[string]string{}
+	for _, ns := range namespaces {
+		current[ns.Name] = ns.ProjectID()
+	}
+
+	skip := func(format string, args ...interface{}) {
+		reason := fmt.Sprintf("%s: %s", recorded.Cluster, fmt.Sprintf(format, args...))
+		logger.Warn(fmt.Sprintf("Skipping %s", reason))
+		summary.Skipped = append(summary.Skipped, reason)
+	}
+
+	for _, want := range recorded.Namespaces {
+		have, exists := current[want.Namespace]
+		if !exists {
+			skip("namespace %s no longer exists", want.Namespace)
+			continue
+		}
+		if want.ProjectID == "" {
+			if have != "" {
+				skip("namespace %s was not in a project and is now in %s", want.Namespace, have)
+			} else {
+				summary.Unchanged++
+			}
+			continue
+		}
+
+		projectID := want.ProjectID
+		if !projectIDs[projectID] {
+			id, ok := projectsByName[want.Project]
+			if !ok || want.Project == "" {
+				skip("project %s (%s) of namespace %s no longer exists", want.Project, want.ProjectID, want.Namespace)
+				continue
+			}
+			logger.Info(fmt.Sprintf("Project %s was recreated as %s", want.Project, id))
+			projectID = id
+		}
+		if have == projectID {
+			summary.Unchanged++
+			continue
+		}
+
+		logger.Info(fmt.Sprintf("Restoring namespace %s from %q to project %s", want.Namespace, have, projectID))
+		if err := AssignNamespaceToProject(&clusterCfg, recorded.ClusterID, want.Namespace, projectID); err != nil {
+			return err
+		}
+		summary.Restored++
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package rancher
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestSnapshotAndRestore(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	teamA := srv.AddProject(cluster.ID, "TeamA")
+	teamB := srv.AddProject(cluster.ID, "TeamB")
+	srv.AddNamespace(cluster.ID, "a-1", teamA.ID)
+	srv.AddNamespace(cluster.ID, "a-2", teamA.ID)
+	srv.AddNamespace(cluster.ID, "b-1", teamB.ID)
+	srv.AddNamespace(cluster.ID, "loose", "")
+	srv.AddNamespace(cluster.ID, "gone", teamB.ID)
+
+	taken, err := TakeSnapshot(cfg)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, taken.Save(path))
+	snapshot, err := LoadSnapshot(path)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Clusters, 1)
+	assert.Contains(t, snapshot.Clusters[0].Namespaces, NamespaceAssignment{Namespace: "a-1", ProjectID: teamA.ID, Project: "TeamA"})
+	assert.Contains(t, snapshot.Clusters[0].Namespaces, NamespaceAssignment{Namespace: "loose"})
+
+	// An accidental bulk move, a namespace added to a project and a deleted namespace.
+	for _, name := range []string{"a-1", "a-2"} {
+		require.NoError(t, AssignNamespaceToProject(cfg, cluster.ID, name, teamB.ID))
+	}
+	require.NoError(t, AssignNamespaceToProject(cfg, cluster.ID, "loose", teamA.ID))
+	srv.UpdateNamespace(cluster.ID, "gone", func(ns *ranchertest.Namespace) { ns.Name = "renamed" })
+
+	summary, err := RestoreSnapshot(cfg, snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Clusters)
+	assert.Equal(t, 2, summary.Restored)
+	assert.Equal(t, 1, summary.Unchanged)
+	assert.Len(t, summary.Skipped, 2)
+	assert.Empty(t, summary.Errors)
+	for _, name := range []string{"a-1", "a-2"} {
+		ns, _ := srv.Namespace(cluster.ID, name)
+		assert.Equal(t, teamA.ID, ns.ProjectID(), name)
+	}
+	ns, _ := srv.Namespace(cluster.ID, "loose")
+	assert.Equal(t, teamA.ID, ns.ProjectID(), "namespaces are never taken out of a project")
+
+	// A project recreated under a new ID is found by name.
+	srv.UpdateProject(cluster.ID, "TeamA", func(p *ranchertest.Project) { p.ID = cluster.ID + ":p-new" })
+	summary, err = RestoreSnapshot(cfg, snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Restored)
+	ns, _ = srv.Namespace(cluster.ID, "a-1")
+	assert.Equal(t, cluster.ID+":p-new", ns.ProjectID())
+
+	// Clusters that are not selected are left alone.
+	cfg.ClusterName = srv.AddCluster(ranchertest.Cluster{Name: "other"}).Name
+	summary, err = RestoreSnapshot(cfg, snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.Clusters)
+}
+
+func TestRestoreSnapshotFromOtherServer(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	team := srv.AddProject(cluster.ID, "Team")
+	srv.AddNamespace(cluster.ID, "apps", "")
+
+	snapshot := &Snapshot{Version: SnapshotVersion, Server: "https://other.example.com", Clusters: []ClusterSnapshot{{
+		ClusterID:  cluster.ID,
+		Cluster:    cluster.Name,
+		Namespaces: []NamespaceAssignment{{Namespace: "apps", ProjectID: team.ID, Project: "Team"}},
+	}}}
+	_, err := RestoreSnapshot(cfg, snapshot)
+	assert.ErrorContains(t, err, "snapshot was taken on Rancher server https://other.example.com")
+	assert.Zero(t, srv.CountRequests("PUT", ""))
+
+	cfg.AllowOtherServer = true
+	summary, err := RestoreSnapshot(cfg, snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Restored)
+
+	// A trailing slash does not make it another server.
+	cfg.AllowOtherServer = false
+	snapshot.Server = cfg.RancherServerURL + "/"
+	_, err = RestoreSnapshot(cfg, snapshot)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,95 @@
+package rancher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/kubeversion"
+)
+
+// SelectClusters returns the clusters selected by the configuration: the named cluster, or every
+// active cluster matching --cluster-type or --cluster-labels and --kubernetes-version.
+func SelectClusters(cfg *config.Config) ([]ClusterRef, error) {
+	if !cfg.SelectsClusters() {
+		clusterID, err := GetClusterID(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return []ClusterRef{{ID: clusterID, Name: cfg.ClusterName}}, nil
+	}
+
+	matches, err := clusterMatcher(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters, err := ListClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list clusters: %v", err))
+		return nil, fmt.Errorf("failed to list clusters: %v", err)
+	}
+
+	var selected []ClusterRef
+	for _, cluster := range clusters {
+		if !cluster.Active() {
+			logger.Warn(fmt.Sprintf("Skipping cluster %s because it is not active", cluster.Name))
+			continue
+		}
+		if matches(cluster) {
+			selected = append(selected, cluster.Ref())
+		}
+	}
+
+	logger.Info(fmt.Sprintf("Selected %d cluster(s)", len(selected)))
+	return selected, nil
+}
+
+// clusterMatcher returns a function reporting whether a cluster matches --cluster-type or
+// --cluster-labels, and --kubernetes-version.
+func clusterMatcher(cfg *config.Config) (func(Cluster) bool, error) {
+	var versions *kubeversion.Range
+	if cfg.KubernetesVersion != "" {
+		r, err := kubeversion.ParseRange(cfg.KubernetesVersion)
+		if err != nil {
+			return nil, err
+		}
+		versions = &r
+	}
+
+	var keyPairs []string
+	if cfg.ClusterLabels != "" {
+		keyPairs = strings.Split(cfg.ClusterLabels, ",")
+	}
+
+	return func(cluster Cluster) bool {
+		if cfg.ClusterType != "" && cluster.Provider != cfg.ClusterType {
+			return false
+		}
+		if len(keyPairs) > 0 && !hasAnyLabel(cluster.Labels, keyPairs) {
+			return false
+		}
+		if versions != nil {
+			version, err := kubeversion.Parse(cluster.KubernetesVersion)
+			if err != nil {
+				logger.Warn(fmt.Sprintf("Skipping cluster %s because its Kubernetes version is unknown: %v", cluster.Name, err))
+				return false
+			}
+			if !versions.Contains(version) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// hasAnyLabel reports whether labels contain any of the key=value pairs.
+func hasAnyLabel(labels map[string]string, keyPairs []string) bool {
+	for _, keyPair := range keyPairs {
+		key, value, _ := strings.Cut(keyPair, "=")
+		if labelValue, exists := labels[key]; exists && labelValue == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package rancher
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/kubeversion"
+)
+
+// How well a Rancher version is supported.
+const (
+	// SupportTested versions are known to work.
+	SupportTested = "tested"
+	// SupportUntested versions are newer than every tested one and are assumed to behave like the newest.
+	SupportUntested = "untested"
+	// SupportUnknown is reported when the server version cannot be read or parsed.
+	SupportUnknown = "unknown"
+	// SupportUnsupported versions lack APIs the tool relies on.
+	SupportUnsupported = "unsupported"
+)
+
+// Capabilities are the API variants used for a Rancher server.
+type Capabilities struct {
+	// ServerVersion is the server-version setting, "" when it cannot be read.
+	ServerVersion string
+	Support       string
+
+	// ClusterAPI and ProjectAPI are config.APINorman, config.APISteve or, when the server
+	// version is unknown, config.APIAuto to try Steve and fall back to Norman.
+	ClusterAPI string
+	ProjectAPI string
+
+	// KubeconfigToken reports whether generated kubeconfigs embed a token. It is false when the
+	// server's kubeconfig-generate-token setting is false, and true when the setting is missing.
+	KubeconfigToken bool
+}
+
+// MinimumServerVersion is the oldest supported Rancher version, the first with the Steve API for
+// provisioning clusters.
+var MinimumServerVersion = kubeversion.Version{Major: 2, Minor: 6}
+
+// compatibility maps Rancher version ranges to their capabilities, oldest first. Versions newer
+// than the last range are untested and get its capabilities.
+var compatibility = []struct {
+	versions     string
+	capabilities Capabilities
+}{
+	{">=2.6,<2.8", Capabilities{
+		ClusterAPI: config.APISteve,
+		ProjectAPI: config.APINorman,
+	}},
+	{">=2.8,<2.12", Capabilities{
+		ClusterAPI: config.APISteve,
+		ProjectAPI: config.APISteve,
+	}},
+}
+
+// unknownCapabilities are used when the server version cannot be determined.
+var unknownCapabilities = Capabilities{
+	Support:    SupportUnknown,
+	ClusterAPI: config.APIAuto,
+	ProjectAPI: config.APINorman,
+}
+
+// CapabilitiesTTL is how long detected capabilities are reused before the server version is read
+// again, so a long-running daemon or API server follows a Rancher upgrade.
+var CapabilitiesTTL = 10 * time.Minute
+
+type detectedCapabilities struct {
+	capabilities *Capabilities
+	detected     time.Time
+}
+
+var (
+	capabilitiesMu sync.Mutex
+	capabilities   = map[string]detectedCapabilities{}
+)
+
+// ServerCapabilities reads the server-version setting of the Rancher server and returns the
+// capabilities the compatibility table lists for it, along with those read from other settings.
+// The result is reused for CapabilitiesTTL.
+func ServerCapabilities(cfg *config.Config) *Capabilities {
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+
+	key := capabilitiesKey(cfg)
+	if d, ok := capabilities[key]; ok && time.Since(d.detected) < CapabilitiesTTL {
+		return d.capabilities
+	}
+
+	c := unknownCapabilities
+	serverVersion, err := GetSetting(cfg, "server-version")
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to read the Rancher server version: %v", err))
+	} else {
+		c = capabilitiesFor(serverVersion)
+	}
+	c.KubeconfigToken = true
+	if value, err := GetSetting(cfg, "kubeconfig-generate-token"); err == nil && value == "false" {
+		c.KubeconfigToken = false
+	}
+	capabilities[key] = detectedCapabilities{capabilities: &c, detected: time.Now()}
+	return &c
+}
+
+// capabilitiesKey identifies the server whose capabilities are cached. Recording and replaying runs
+// detect on their own, so a cassette always holds the request.
+func capabilitiesKey(cfg *config.Config) string {
+	return fmt.Sprintf("%s %s %s", cfg.RancherServerURL, cfg.RecordFile, cfg.ReplayFile)
+}
+
+// capabilitiesFor looks up a server version in the compatibility table.
+func capabilitiesFor(serverVersion string) Capabilities {
+	c := unknownCapabilities
+	c.ServerVersion = serverVersion
+
+	version, err := kubeversion.Parse(serverVersion)
+	if err != nil {
+		return c
+	}
+	if version.Compare(MinimumServerVersion) < 0 {
+		c.Support = SupportUnsupported
+		return c
+	}
+
+	for _, entry := range compatibility {
+		r, err := kubeversion.ParseRange(entry.versions)
+		if err != nil {
+			panic(fmt.Sprintf("invalid compatibility range %q: %v", entry.versions, err))
+		}
+		c = entry.capabilities
+		if r.Contains(version) {
+			c.Support = SupportTested
+			c.ServerVersion = serverVersion
+			return c
+		}
+	}
+
+	// Newer than every tested version: assume it behaves like the newest.
+	c.Support = SupportUntested
+	c.ServerVersion = serverVersion
+	return c
+}
@@ -0,0 +1,99 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+func TestCapabilitiesFor(t *testing.T) {
+	tests := []struct {
+		serverVersion string
+		support       string
+		clusterAPI    string
+		projectAPI    string
+	}{
+		{"v2.5.16", SupportUnsupported, config.APIAuto, config.APINorman},
+		{"v2.6.0", SupportTested, config.APISteve, config.APINorman},
+		{"v2.7.10", SupportTested, config.APISteve, config.APINorman},
+		{"v2.8.0", SupportTested, config.APISteve, config.APISteve},
+		{"v2.11.3", SupportTested, config.APISteve, config.APISteve},
+		{"v2.13.1", SupportUntested, config.APISteve, config.APISteve},
+		{"dev", SupportUnknown, config.APIAuto, config.APINorman},
+	}
+	for _, tt := range tests {
+		c := capabilitiesFor(tt.serverVersion)
+		assert.Equal(t, tt.serverVersion, c.ServerVersion)
+		assert.Equal(t, tt.support, c.Support, tt.serverVersion)
+		assert.Equal(t, tt.clusterAPI, c.ClusterAPI, tt.serverVersion)
+		assert.Equal(t, tt.projectAPI, c.ProjectAPI, tt.serverVersion)
+	}
+}
+
+func TestServerCapabilitiesKubeconfigToken(t *testing.T) {
+	tests := []struct {
+		serverVersion string
+		setting       string
+		want          bool
+	}{
+		// Older servers have no kubeconfig-generate-token setting and always embed a token.
+		{"v2.5.16", "", true},
+		{"v2.7.10", "false", false},
+		{"v2.9.2", "true", true},
+		{"v2.11.3", "false", false},
+	}
+	for _, tt := range tests {
+		srv, cfg, _ := newTestServer(t)
+		srv.SetSetting("server-version", tt.serverVersion)
+		if tt.setting != "" {
+			srv.SetSetting("kubeconfig-generate-token", tt.setting)
+		}
+		assert.Equal(t, tt.want, ServerCapabilities(cfg).KubeconfigToken, tt.serverVersion)
+	}
+}
+
+func TestVerifyAccessServerVersion(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	srv.SetSetting("server-version", "v2.5.16")
+	err := VerifyAccess(cfg)
+	assert.ErrorContains(t, err, "unsupported Rancher version v2.5.16")
+
+	srv, cfg, _ = newTestServer(t)
+	srv.SetSetting("server-version", "v2.5.16")
+	cfg.SkipVersionCheck = true
+	require.NoError(t, VerifyAccess(cfg))
+
+	srv, cfg, _ = newTestServer(t)
+	srv.SetSetting("server-version", "v2.9.2")
+	require.NoError(t, VerifyAccess(cfg))
+	assert.Equal(t, config.APISteve, ClusterAPI(cfg))
+	assert.Equal(t, 1, srv.CountRequests("GET", "/v3/settings/server-version"))
+
+	// A server that hides its version is still usable.
+	srv, cfg, _ = newTestServer(t)
+	srv.Fail("GET", "/v3/settings/server-version", 403)
+	require.NoError(t, VerifyAccess(cfg))
+	assert.Equal(t, config.APIAuto, ClusterAPI(cfg))
+}
+
+func TestServerCapabilitiesExpire(t *testing.T) {
+	srv, cfg, _ := newTestServer(t)
+	srv.SetSetting("server-version", "v2.7.10")
+	assert.Equal(t, config.APINorman, ProjectAPI(cfg))
+
+	srv.SetSetting("server-version", "v2.9.2")
+	assert.Equal(t, config.APINorman, ProjectAPI(cfg), "capabilities are reused within the TTL")
+	assert.Equal(t, 1, srv.CountRequests("GET", "/v3/settings/server-version"))
+
+	capabilitiesMu.Lock()
+	d := capabilities[capabilitiesKey(cfg)]
+	d.detected = d.detected.Add(-CapabilitiesTTL)
+	capabilities[capabilitiesKey(cfg)] = d
+	capabilitiesMu.Unlock()
+
+	assert.Equal(t, config.APISteve, ProjectAPI(cfg), "an upgrade is detected once the TTL has passed")
+	assert.Equal(t, 2, srv.CountRequests("GET", "/v3/settings/server-version"))
+}
@@ -0,0 +1,30 @@
+package rancher
+
+import (
+	"fmt"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/podsecurity"
+)
+
+// SetProjectPodSecurity records a Pod Security level on a project. Namespaces assigned to the
+// project afterwards get the matching Pod Security Admission labels. Other annotations of the
+// project are kept.
+func SetProjectPodSecurity(cfg *config.Config, clusterID, projectID, level string) error {
+	project, err := GetProject(cfg, projectID)
+	if err != nil {
+		return err
+	}
+	if project.Annotations[podsecurity.Annotation] == level {
+		logger.Debug(fmt.Sprintf("Project %s already has Pod Security level %s", projectID, level))
+		return nil
+	}
+
+	logger.Info(fmt.Sprintf("Setting Pod Security level of project %s to %s...", projectID, level))
+	annotations := map[string]string{}
+	for k, v := range project.Annotations {
+		annotations[k] = v
+	}
+	annotations[podsecurity.Annotation] = level
+	return UpdateProject(cfg, clusterID, projectID, map[string]interface{}{"annotations": annotations})
+}
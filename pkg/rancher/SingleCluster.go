@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/notify"
 )
 
 // SingleCluster processes a single cluster by verifying it, handling projects within it, and optionally generating a kubeconfig.
@@ -23,12 +24,11 @@ func SingleCluster(cfg *config.Config) error {
 		return fmt.Errorf("error getting cluster ID: %v", err)
 	}
 
-	if cfg.ProjectName != "" {
-		logger.Info(fmt.Sprintf("Processing project '%s' in cluster '%s'...", cfg.ProjectName, clusterID))
-		if err := MainProject(cfg, clusterID); err != nil {
-			logger.Error(fmt.Sprintf("Error handling project '%s': %v", cfg.ProjectName, err))
-			return fmt.Errorf("error handling project '%s': %v", cfg.ProjectName, err)
-		}
+	logger.Info(fmt.Sprintf("Processing project '%s' in cluster '%s'...", cfg.ProjectName, clusterID))
+	if err := MainProject(cfg, clusterID); err != nil {
+		logger.Error(fmt.Sprintf("Error handling project '%s': %v", cfg.ProjectName, err))
+		Notify(cfg, notify.Event{Type: notify.ClusterFailed, Cluster: cfg.ClusterName, ClusterID: clusterID, Project: cfg.ProjectName, Error: err.Error()})
+		return fmt.Errorf("error handling project '%s': %v", cfg.ProjectName, err)
 	}
 
 	logger.Info("Single cluster processing completed successfully.")
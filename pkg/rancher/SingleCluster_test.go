@@ -0,0 +1,90 @@
+package rancher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestSingleClusterCreatesAndAssigns(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	cfg.ProjectName = "ClusterServices"
+	cfg.Namespace = "monitoring"
+	cfg.CreateProject = true
+	cfg.CreateNamespace = true
+	cfg.CreateKubeconfig = true
+	cfg.KubeconfigFile = filepath.Join(t.TempDir(), "kubeconfig")
+
+	require.NoError(t, SingleCluster(cfg))
+
+	project, ok := srv.Project(cluster.ID, "ClusterServices")
+	require.True(t, ok, "project should have been created")
+
+	ns, ok := srv.Namespace(cluster.ID, "monitoring")
+	require.True(t, ok, "namespace should have been created")
+	assert.Equal(t, project.ID, ns.ProjectID())
+
+	data, err := os.ReadFile(cfg.KubeconfigFile)
+	require.NoError(t, err)
+	assert.Equal(t, ranchertest.Kubeconfig(srv.URL, cluster.ID, cluster.Name), string(data))
+}
+
+func TestSingleClusterIsIdempotent(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	project := srv.AddProject(cluster.ID, "ClusterServices")
+	srv.AddNamespace(cluster.ID, "monitoring", project.ID)
+	cfg.ProjectName = "ClusterServices"
+	cfg.Namespace = "monitoring"
+	cfg.CreateProject = true
+	cfg.CreateNamespace = true
+
+	require.NoError(t, SingleCluster(cfg))
+
+	assert.Len(t, srv.Projects(cluster.ID), 1)
+	assert.Zero(t, srv.CountRequests("POST", "/v3/projects"))
+}
+
+func TestSingleClusterWithoutCreate(t *testing.T) {
+	_, cfg, _ := newTestServer(t)
+	cfg.ProjectName = "Missing"
+
+	assert.ErrorContains(t, SingleCluster(cfg), "project Missing not found")
+}
+
+func TestProjectNamesAreEscaped(t *testing.T) {
+	srv, cfg, cluster := newTestServer(t)
+	other := srv.AddCluster(ranchertest.Cluster{Name: "other"})
+	srv.AddProject(other.ID, "x")
+	cfg.ProjectName = "Team A"
+	cfg.CreateProject = true
+
+	require.NoError(t, SingleCluster(cfg))
+	_, ok := srv.Project(cluster.ID, "Team A")
+	assert.True(t, ok, "project should have been created")
+
+	// A name cannot add query parameters selecting projects of another cluster.
+	_, err := GetProjectInfo(cfg, cluster.ID, "x&clusterId="+other.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSingleClusterUnknownCluster(t *testing.T) {
+	_, cfg, _ := newTestServer(t)
+	cfg.ClusterName = "nope"
+
+	assert.ErrorContains(t, SingleCluster(cfg), "failed to find cluster ID")
+}
+
+func TestKubeconfigPath(t *testing.T) {
+	_, cfg, _ := newTestServer(t)
+	cfg.KubeconfigDir = "/tmp/kube"
+	cfg.KubeconfigPrefix = "rancher-"
+	assert.Equal(t, "/tmp/kube/rancher-prod", KubeconfigPath(cfg, "prod"))
+
+	cfg.KubeconfigFile = "explicit"
+	assert.Equal(t, "explicit", KubeconfigPath(cfg, "prod"))
+}
@@ -0,0 +1,105 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// SnapshotVersion is the snapshot file format version.
+const SnapshotVersion = 1
+
+// Snapshot records which project every namespace of a set of clusters is assigned to.
+type Snapshot struct {
+	Version  int               `json:"version"`
+	Created  time.Time         `json:"created"`
+	Server   string            `json:"server"`
+	Clusters []ClusterSnapshot `json:"clusters"`
+}
+
+// ClusterSnapshot holds the namespace assignments of one cluster.
+type ClusterSnapshot struct {
+	Cluster    string                `json:"cluster"`
+	ClusterID  string                `json:"clusterId"`
+	Namespaces []NamespaceAssignment `json:"namespaces"`
+}
+
+// NamespaceAssignment is the project a namespace was assigned to. ProjectID and Project are
+// empty for namespaces outside any project.
+type NamespaceAssignment struct {
+	Namespace string `json:"namespace"`
+	ProjectID string `json:"projectId,omitempty"`
+	Project   string `json:"project,omitempty"`
+}
+
+// TakeSnapshot records the field.cattle.io/projectId annotation of every namespace in the
+// selected clusters, together with the project name so a recreated project can be found again.
+func TakeSnapshot(cfg *config.Config) (*Snapshot, error) {
+	logger.Info("Taking snapshot of namespace assignments...")
+
+	clusters, err := SelectClusters(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to select clusters: %v", err))
+		return nil, fmt.Errorf("failed to select clusters: %v", err)
+	}
+
+	snapshot := &Snapshot{Version: SnapshotVersion, Created: time.Now().UTC(), Server: cfg.RancherServerURL, Clusters: []ClusterSnapshot{}}
+	for _, cluster := range clusters {
+		projects, err := ListProjects(cfg, cluster.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot cluster %s: %v", cluster.Name, err)
+		}
+		namespaces, err := ListNamespaces(cfg, cluster.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot cluster %s: %v", cluster.Name, err)
+		}
+
+		projectNames := map[string]string{}
+		for _, p := range projects {
+			projectNames[p.Id] = p.Name
+		}
+		result := ClusterSnapshot{Cluster: cluster.Name, ClusterID: cluster.ID, Namespaces: []NamespaceAssignment{}}
+		for _, ns := range namespaces {
+			result.Namespaces = append(result.Namespaces, NamespaceAssignment{
+				Namespace: ns.Name,
+				ProjectID: ns.ProjectID(),
+				Project:   projectNames[ns.ProjectID()],
+			})
+		}
+
+		logger.Info(fmt.Sprintf("Recorded %d namespace(s) in cluster %s", len(result.Namespaces), cluster.Name))
+		snapshot.Clusters = append(snapshot.Clusters, result)
+	}
+	return snapshot, nil
+}
+
+// Save writes the snapshot to path.
+func (s *Snapshot) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot reads a snapshot written by Save.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d in %s", s.Version, path)
+	}
+	return &s, nil
+}
@@ -0,0 +1,55 @@
+package rancher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/supporttools/rancher-projects/pkg/audit"
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// UpdateProject sets the given fields of a project, e.g. labels or resourceQuota. Fields not
+// included in changes are left as they are.
+func UpdateProject(cfg *config.Config, clusterID, projectID string, changes map[string]interface{}) (err error) {
+	if ProjectAPI(cfg) == config.APISteve {
+		return UpdateSteveProject(cfg, clusterID, projectID, changes)
+	}
+	logger.Info(fmt.Sprintf("Updating project %s in cluster %s...", projectID, clusterID))
+
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to marshal project update: %v", err))
+		return fmt.Errorf("failed to marshal project update: %v", err)
+	}
+	logger.Debug(fmt.Sprintf("Generated payload for project update: %s", string(payload)))
+
+	url := fmt.Sprintf("%s/v3/projects/%s", cfg.RancherServerURL, projectID)
+	req, err := NewRequest(cfg, "PUT", url, bytes.NewReader(payload))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	entry := audit.Entry{Action: audit.ActionUpdateProject, ClusterID: clusterID, Object: "project/" + projectID, After: string(payload)}
+	defer func() { auditChange(cfg, &entry, err) }()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Failed to update project %s, status code: %d", projectID, resp.StatusCode))
+		return fmt.Errorf("failed to update project %s, status code: %d", projectID, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package rancher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/supporttools/rancher-projects/pkg/audit"
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// steveProjectSpecFields maps the /v3/projects fields UpdateSteveProject supports, other than labels
+// and annotations, to the spec fields of a management.cattle.io Project.
+var steveProjectSpecFields = map[string]string{
+	"name":                          "displayName",
+	"description":                   "description",
+	"resourceQuota":                 "resourceQuota",
+	"namespaceDefaultResourceQuota": "namespaceDefaultResourceQuota",
+	"containerDefaultResourceLimit": "containerDefaultResourceLimit",
+}
+
+// UpdateSteveProject sets the given /v3/projects fields of a project, e.g. labels or resourceQuota,
+// on its management.cattle.io Project resource. Labels and annotations are merged into the metadata,
+// so keys set by Rancher or other tools are kept; a key with a nil value is removed. The other
+// fields go to the spec, see steveProjectSpecFields; any other field is an error. Fields not
+// included in changes are left as they are.
+func UpdateSteveProject(cfg *config.Config, clusterID, projectID string, changes map[string]interface{}) (err error) {
+	logger.Info(fmt.Sprintf("Updating project %s in cluster %s...", projectID, clusterID))
+
+	for field := range changes {
+		if _, ok := steveProjectSpecFields[field]; !ok && field != "labels" && field != "annotations" {
+			logger.Error(fmt.Sprintf("Project field %s is not supported by the Steve API", field))
+			return fmt.Errorf("project field %q is not supported by the Steve API", field)
+		}
+	}
+
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to marshal project update: %v", err))
+		return fmt.Errorf("failed to marshal project update: %v", err)
+	}
+	logger.Debug(fmt.Sprintf("Generated payload for project update: %s", string(payload)))
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	// The resource is replaced as a whole, so start from the current object. Its resourceVersion
+	// makes the update fail instead of overwriting a concurrent change.
+	req, err := NewRequest(cfg, "GET", steveProjectURL(cfg, projectID), http.NoBody)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Failed to get project %s, status code: %d", projectID, resp.StatusCode))
+		return fmt.Errorf("failed to get project %s, status code: %d", projectID, resp.StatusCode)
+	}
+
+	var object map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&object); err != nil {
+		logger.Error(fmt.Sprintf("Failed to decode JSON response: %v", err))
+		return fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+	metadata, _ := object["metadata"].(map[string]interface{})
+	spec, _ := object["spec"].(map[string]interface{})
+	if metadata == nil || spec == nil {
+		return fmt.Errorf("project %s has no metadata or spec", projectID)
+	}
+	for field, value := range changes {
+		switch field {
+		case "labels", "annotations":
+			merged, err := mergeStringMap(metadata[field], value)
+			if err != nil {
+				return fmt.Errorf("invalid project %s: %v", field, err)
+			}
+			metadata[field] = merged
+		default:
+			spec[steveProjectSpecFields[field]] = value
+		}
+	}
+
+	body, err := json.Marshal(object)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to marshal project update: %v", err))
+		return fmt.Errorf("failed to marshal project update: %v", err)
+	}
+	req, err = NewRequest(cfg, "PUT", steveProjectURL(cfg, projectID), bytes.NewReader(body))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	entry := audit.Entry{Action: audit.ActionUpdateProject, ClusterID: clusterID, Object: "project/" + projectID, After: string(payload)}
+	defer func() { auditChange(cfg, &entry, err) }()
+
+	resp, err = client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send HTTP request: %v", err))
+		return fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Failed to update project %s, status code: %d", projectID, resp.StatusCode))
+		return fmt.Errorf("failed to update project %s, status code: %d", projectID, resp.StatusCode)
+	}
+	return nil
+}
+
+// mergeStringMap returns current with the keys of changes set, or removed when their value is nil.
+// changes may be any map that encodes to a JSON object, e.g. a map[string]string.
+func mergeStringMap(current, changes interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+	var updates map[string]interface{}
+	if err := json.Unmarshal(data, &updates); err != nil {
+		return nil, err
+	}
+
+	merged := map[string]interface{}{}
+	if existing, ok := current.(map[string]interface{}); ok {
+		for key, value := range existing {
+			merged[key] = value
+		}
+	}
+	for key, value := range updates {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged, nil
+}
@@ -1,32 +1,30 @@
 package rancher
 
 import (
-	"encoding/base64"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/supporttools/rancher-projects/pkg/config"
 )
 
-// VerifyAccess checks if the provided credentials have access to the Rancher server.
+// VerifyAccess checks if the provided credentials have access to the Rancher server and that its
+// version is supported, see ServerCapabilities. Unsupported versions are refused unless
+// --skip-version-check is set.
 func VerifyAccess(cfg *config.Config) error {
 	logger.Info("Verifying access to Rancher server...")
 
-	authHeader := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", cfg.RancherAccessKey, cfg.RancherSecretKey)))
 	url := fmt.Sprintf("%s/v3/", cfg.RancherServerURL)
 	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
 
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
 		return fmt.Errorf("failed to create HTTP request: %v", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", authHeader))
 
-	client := &http.Client{
-		Timeout: time.Second * 10, // 10 seconds timeout
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
 	}
 
 	logger.Info("Sending GET request to verify Rancher access...")
@@ -43,5 +41,23 @@ func VerifyAccess(cfg *config.Config) error {
 	}
 
 	logger.Info(fmt.Sprintf("Successfully authenticated to %s", cfg.RancherServerURL))
+
+	caps := ServerCapabilities(cfg)
+	switch caps.Support {
+	case SupportUnsupported:
+		if !cfg.SkipVersionCheck {
+			logger.Error(fmt.Sprintf("Rancher %s is not supported, the minimum version is v%s", caps.ServerVersion, MinimumServerVersion))
+			return fmt.Errorf("unsupported Rancher version %s: the minimum version is v%s, set --skip-version-check to continue anyway", caps.ServerVersion, MinimumServerVersion)
+		}
+		logger.Warn(fmt.Sprintf("Rancher %s is not supported, continuing because of --skip-version-check", caps.ServerVersion))
+	case SupportUntested:
+		logger.Warn(fmt.Sprintf("Rancher %s is newer than the versions rancher-projects was tested with", caps.ServerVersion))
+	case SupportUnknown:
+		if caps.ServerVersion != "" {
+			logger.Warn(fmt.Sprintf("Cannot parse Rancher version %q", caps.ServerVersion))
+		}
+	}
+	logger.Info(fmt.Sprintf("Rancher %s: discovering clusters through the %s API, managing projects through the %s API",
+		caps.ServerVersion, ClusterAPI(cfg), ProjectAPI(cfg)))
 	return nil
 }
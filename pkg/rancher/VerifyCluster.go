@@ -1,11 +1,9 @@
 package rancher
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/supporttools/rancher-projects/pkg/config"
 )
@@ -14,21 +12,18 @@ import (
 func VerifyCluster(cfg *config.Config) error {
 	logger.Info(fmt.Sprintf("Verifying cluster %s...", cfg.ClusterName))
 
-	url := fmt.Sprintf("%s/v3/clusters?name=%s", cfg.RancherServerURL, cfg.ClusterName)
+	url := clusterQuery(cfg, cfg.ClusterName)
 	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
 
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create HTTP request: %v", err))
 		return fmt.Errorf("failed to create HTTP request: %v", err)
 	}
 
-	authHeader := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", cfg.RancherAccessKey, cfg.RancherSecretKey)))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", authHeader))
-
-	client := &http.Client{
-		Timeout: time.Second * 10, // 10 seconds timeout
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
 	}
 
 	logger.Info(fmt.Sprintf("Sending GET request to verify cluster %s...", cfg.ClusterName))
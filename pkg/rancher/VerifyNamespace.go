@@ -3,7 +3,6 @@ package rancher
 import (
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/supporttools/rancher-projects/pkg/config"
 )
@@ -15,16 +14,15 @@ func VerifyNamespace(cfg *config.Config, clusterID, namespace string) error {
 	url := fmt.Sprintf("%s/k8s/clusters/%s/v1/namespaces/%s", cfg.RancherServerURL, clusterID, namespace)
 	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
 
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create HTTP request for namespace %s: %v", namespace, err))
 		return fmt.Errorf("failed to create HTTP request for namespace %s: %v", namespace, err)
 	}
-	req.SetBasicAuth(cfg.RancherAccessKey, cfg.RancherSecretKey)
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{
-		Timeout: 10 * time.Second, // More idiomatic timeout setup
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
 	}
 
 	logger.Info(fmt.Sprintf("Sending GET request to verify namespace %s in cluster %s...", namespace, clusterID))
@@ -41,7 +39,7 @@ func VerifyNamespace(cfg *config.Config, clusterID, namespace string) error {
 		return nil
 	case http.StatusNotFound:
 		logger.Error(fmt.Sprintf("Namespace %s not found in cluster %s", namespace, clusterID))
-		return fmt.Errorf("namespace %s not found in cluster %s", namespace, clusterID)
+		return fmt.Errorf("namespace %s not found in cluster %s: %w", namespace, clusterID, ErrNotFound)
 	default:
 		logger.Error(fmt.Sprintf("Unexpected status code %d while verifying namespace %s in cluster %s", resp.StatusCode, namespace, clusterID))
 		return fmt.Errorf("unexpected status code %d while verifying namespace %s in cluster %s", resp.StatusCode, namespace, clusterID)
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/supporttools/rancher-projects/pkg/config"
 )
@@ -14,19 +13,31 @@ import (
 func VerifyProject(cfg *config.Config, clusterID, projectName string) error {
 	logger.Info(fmt.Sprintf("Verifying project %s in cluster %s...", projectName, clusterID))
 
-	url := fmt.Sprintf("%s/v3/projects?clusterId=%s&name=%s", cfg.RancherServerURL, clusterID, projectName)
+	if ProjectAPI(cfg) == config.APISteve {
+		project, err := findSteveProject(cfg, clusterID, projectName)
+		if err != nil {
+			return fmt.Errorf("failed to verify project %s: %v", projectName, err)
+		}
+		if project == nil {
+			logger.Error(fmt.Sprintf("Project %s not found in cluster %s", projectName, clusterID))
+			return fmt.Errorf("project %s not found in cluster %s", projectName, clusterID)
+		}
+		logger.Info(fmt.Sprintf("Successfully verified project %s exists in cluster %s.", projectName, clusterID))
+		return nil
+	}
+
+	url := projectQuery(cfg, clusterID, projectName)
 	logger.Debug(fmt.Sprintf("Generated request URL: %s", url))
 
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := NewRequest(cfg, "GET", url, http.NoBody)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create HTTP request for project %s: %v", projectName, err))
 		return fmt.Errorf("failed to create HTTP request for project %s: %v", projectName, err)
 	}
-	req.SetBasicAuth(cfg.RancherAccessKey, cfg.RancherSecretKey)
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{
-		Timeout: time.Second * 10, // 10 seconds timeout
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return err
 	}
 
 	logger.Info(fmt.Sprintf("Sending GET request to verify project %s in cluster %s...", projectName, clusterID))
@@ -0,0 +1,28 @@
+package rancher
+
+import (
+	"os"
+	"testing"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+func TestMain(m *testing.M) {
+	// Namespaces created in the fake server are usable immediately.
+	NamespaceSettleDelay = 0
+	os.Exit(m.Run())
+}
+
+// newTestServer starts a fake Rancher server with a single active cluster and
+// returns the server, a configuration pointing at it and the cluster.
+func newTestServer(t *testing.T) (*ranchertest.Server, *config.Config, ranchertest.Cluster) {
+	t.Helper()
+	srv := ranchertest.NewServer()
+	t.Cleanup(srv.Close)
+
+	cluster := srv.AddCluster(ranchertest.Cluster{Name: "downstream", Provider: "rke2"})
+	cfg := srv.Config()
+	cfg.ClusterName = cluster.Name
+	return srv, cfg, cluster
+}
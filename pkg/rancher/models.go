@@ -1,6 +1,7 @@
 package rancher
 
 import (
+	"errors"
 	"time"
 
 	"github.com/supporttools/rancher-projects/pkg/logging"
@@ -10,6 +11,70 @@ var (
 	logger = logging.SetupLogging()
 )
 
+// ErrNotFound is wrapped by errors reporting that a cluster, project or namespace does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ProjectIDAnnotation is the namespace annotation (and label) that links a namespace to a Rancher project.
+const ProjectIDAnnotation = "field.cattle.io/projectId"
+
+// ClusterRef identifies a downstream cluster by ID and name.
+type ClusterRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Cluster is a downstream cluster as reported by the Rancher management API.
+type Cluster struct {
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	Provider          string            `json:"provider"`
+	Driver            string            `json:"driver"`
+	State             string            `json:"state"`
+	KubernetesVersion string            `json:"kubernetesVersion"`
+	NodeCount         int               `json:"nodeCount"`
+	CPU               string            `json:"cpu"`
+	Memory            string            `json:"memory"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	Created           time.Time         `json:"created"`
+
+	// Only known when the cluster was discovered through the Steve API.
+	FleetWorkspace string        `json:"fleetWorkspace,omitempty"`
+	MachinePools   []MachinePool `json:"machinePools,omitempty"`
+}
+
+// MachinePool is a machine pool of a cluster provisioned by Rancher.
+type MachinePool struct {
+	Name     string   `json:"name"`
+	Quantity int      `json:"quantity"`
+	Roles    []string `json:"roles,omitempty"`
+	// MachineConfig is the <kind>/<name> of the pool's machine configuration.
+	MachineConfig string `json:"machineConfig,omitempty"`
+}
+
+// Ref returns the ID and name of the cluster.
+func (c Cluster) Ref() ClusterRef {
+	return ClusterRef{ID: c.ID, Name: c.Name}
+}
+
+// Active reports whether Rancher can reach the cluster.
+func (c Cluster) Active() bool {
+	return c.State == "active"
+}
+
+// Namespace is the metadata of a namespace in a downstream cluster.
+type Namespace struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Created     time.Time         `json:"creationTimestamp"`
+}
+
+// ProjectID returns the <clusterID>:<projectID> the namespace is assigned to, or "" when unassigned.
+func (n Namespace) ProjectID() string {
+	return n.Annotations[ProjectIDAnnotation]
+}
+
 type RancherResponse struct {
 	Type         string      `json:"type"`
 	Links        Links       `json:"links"`
@@ -82,6 +147,7 @@ type Project struct {
 	Created                 time.Time         `json:"created"`
 	CreatedTS               int64             `json:"createdTS"`
 	CreatorId               string            `json:"creatorId"`
+	Description             string            `json:"description"`
 	EnableProjectMonitoring bool              `json:"enableProjectMonitoring"`
 	Id                      string            `json:"id"`
 	Labels                  map[string]string `json:"labels"`
@@ -93,6 +159,28 @@ type Project struct {
 	TransitioningMessage    string            `json:"transitioningMessage"`
 	Type                    string            `json:"type"`
 	Uuid                    string            `json:"uuid"`
+
+	ResourceQuota                 map[string]interface{} `json:"resourceQuota,omitempty"`
+	NamespaceDefaultResourceQuota map[string]interface{} `json:"namespaceDefaultResourceQuota,omitempty"`
+	ContainerDefaultResourceLimit map[string]interface{} `json:"containerDefaultResourceLimit,omitempty"`
+}
+
+// ProjectMember is a project role template binding granting a user or group a role in a project.
+type ProjectMember struct {
+	ID               string `json:"id,omitempty"`
+	ProjectID        string `json:"projectId"`
+	RoleTemplateID   string `json:"roleTemplateId"`
+	UserPrincipalID  string `json:"userPrincipalId,omitempty"`
+	GroupPrincipalID string `json:"groupPrincipalId,omitempty"`
+}
+
+// ProjectNetworkPolicy is the Rancher object through which a project's namespaces are isolated
+// from other projects when project network isolation is enabled for the cluster.
+type ProjectNetworkPolicy struct {
+	ID          string `json:"id,omitempty"`
+	ProjectID   string `json:"projectId"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
 }
 
 type ProjectActions struct {
@@ -0,0 +1,803 @@
+package ranchertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /v3/{$}", s.handleRoot)
+	mux.HandleFunc("GET /v3/settings/{name}", s.handleGetSetting)
+	mux.HandleFunc("GET /v3/users", s.handleListUsers)
+	mux.HandleFunc("GET /v3/clusters", s.handleListClusters)
+	mux.HandleFunc("GET /v3/clusters/{id}", s.handleGetCluster)
+	mux.HandleFunc("POST /v3/clusters/{id}", s.handleClusterAction)
+	mux.HandleFunc("GET /v1/management.cattle.io.clusters", s.handleListManagementClusters)
+	mux.HandleFunc("GET /v1/provisioning.cattle.io.clusters", s.handleListProvisioningClusters)
+	mux.HandleFunc("GET /v1/management.cattle.io.projects/{namespace}", s.handleListSteveProjects)
+	mux.HandleFunc("POST /v1/management.cattle.io.projects", s.handleCreateSteveProject)
+	mux.HandleFunc("GET /v1/management.cattle.io.projects/{namespace}/{name}", s.handleGetSteveProject)
+	mux.HandleFunc("PUT /v1/management.cattle.io.projects/{namespace}/{name}", s.handleUpdateSteveProject)
+	mux.HandleFunc("GET /v3/projects", s.handleListProjects)
+	mux.HandleFunc("POST /v3/projects", s.handleCreateProject)
+	mux.HandleFunc("GET /v3/projects/{id}", s.handleGetProject)
+	mux.HandleFunc("PUT /v3/projects/{id}", s.handleUpdateProject)
+	mux.HandleFunc("GET /v3/projectroletemplatebindings", s.handleListProjectMembers)
+	mux.HandleFunc("POST /v3/projectroletemplatebindings", s.handleCreateProjectMember)
+	mux.HandleFunc("GET /v3/projectnetworkpolicies", s.handleListProjectNetworkPolicies)
+	mux.HandleFunc("POST /v3/projectnetworkpolicies", s.handleCreateProjectNetworkPolicy)
+	mux.HandleFunc("GET /k8s/clusters/{cluster}/v1/namespaces", s.handleListNamespaces)
+	mux.HandleFunc("POST /k8s/clusters/{cluster}/v1/namespaces", s.handleCreateNamespace)
+	mux.HandleFunc("GET /k8s/clusters/{cluster}/v1/namespaces/{name}", s.handleGetNamespace)
+	mux.HandleFunc("PUT /k8s/clusters/{cluster}/v1/namespaces/{name}", s.handleUpdateNamespace)
+	mux.HandleFunc("POST /k8s/clusters/{cluster}/v1/networking.k8s.io.networkpolicies", s.handleCreateNetworkPolicy)
+
+	return s.middleware(mux)
+}
+
+func (s *Server) handleRoot(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"type":    "apiRoot",
+		"links":   map[string]string{"self": s.URL + "/v3"},
+		"baseUrl": s.URL + "/v3",
+	})
+}
+
+func (s *Server) handleGetSetting(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	s.mu.Lock()
+	value, ok := s.settings[name]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("setting %s not found", name))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"id": name, "type": "setting", "name": name, "value": value})
+}
+
+func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("me") != "true" {
+		writeError(w, http.StatusForbidden, "only the current user can be listed")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": []map[string]interface{}{
+		{"id": "u-" + s.Username, "type": "user", "username": s.Username, "name": s.Username},
+	}})
+}
+
+func (s *Server) handleListClusters(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := []interface{}{}
+	for _, c := range s.clusters {
+		if name == "" || c.Name == name {
+			data = append(data, s.clusterJSON(c))
+		}
+	}
+	writeJSON(w, http.StatusOK, collection("cluster", data))
+}
+
+func (s *Server) handleGetCluster(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findCluster(r.PathValue("id"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("cluster %s not found", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.clusterJSON(c))
+}
+
+func (s *Server) handleClusterAction(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findCluster(r.PathValue("id"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("cluster %s not found", r.PathValue("id")))
+		return
+	}
+	if action := r.URL.Query().Get("action"); action != "generateKubeconfig" {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("invalid action %s", action))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"type":   "generateKubeConfigOutput",
+		"config": Kubeconfig(s.URL, c.ID, c.Name),
+	})
+}
+
+// Kubeconfig returns the kubeconfig the fake server generates for a cluster.
+func Kubeconfig(serverURL, clusterID, clusterName string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: "%[3]s"
+  cluster:
+    server: "%[1]s/k8s/clusters/%[2]s"
+users:
+- name: "%[3]s"
+  user:
+    token: "kubeconfig-user-fake:%[2]s"
+contexts:
+- name: "%[3]s"
+  context:
+    user: "%[3]s"
+    cluster: "%[3]s"
+current-context: "%[3]s"
+`, serverURL, clusterID, clusterName)
+}
+
+func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := []interface{}{}
+	for _, p := range s.projects {
+		if query.Get("clusterId") != "" && p.ClusterID != query.Get("clusterId") {
+			continue
+		}
+		if query.Get("name") != "" && p.Name != query.Get("name") {
+			continue
+		}
+		data = append(data, projectJSON(p))
+	}
+	writeJSON(w, http.StatusOK, collection("project", data))
+}
+
+func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.projects {
+		if p.ID == r.PathValue("id") {
+			writeJSON(w, http.StatusOK, projectJSON(p))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("project %s not found", r.PathValue("id")))
+}
+
+// projectBody is the writable part of a project. Fields that are absent are left unchanged by an update.
+type projectBody struct {
+	Name                          string                 `json:"name"`
+	ClusterID                     string                 `json:"clusterId"`
+	Description                   *string                `json:"description"`
+	Labels                        map[string]string      `json:"labels"`
+	Annotations                   map[string]string      `json:"annotations"`
+	ResourceQuota                 map[string]interface{} `json:"resourceQuota"`
+	NamespaceDefaultResourceQuota map[string]interface{} `json:"namespaceDefaultResourceQuota"`
+	ContainerDefaultResourceLimit map[string]interface{} `json:"containerDefaultResourceLimit"`
+}
+
+func (b *projectBody) apply(p *Project) {
+	if b.Description != nil {
+		p.Description = *b.Description
+	}
+	if b.Labels != nil {
+		p.Labels = copyMap(b.Labels)
+	}
+	if b.Annotations != nil {
+		p.Annotations = copyMap(b.Annotations)
+	}
+	if b.ResourceQuota != nil {
+		p.ResourceQuota = b.ResourceQuota
+	}
+	if b.NamespaceDefaultResourceQuota != nil {
+		p.NamespaceDefaultResourceQuota = b.NamespaceDefaultResourceQuota
+	}
+	if b.ContainerDefaultResourceLimit != nil {
+		p.ContainerDefaultResourceLimit = b.ContainerDefaultResourceLimit
+	}
+}
+
+func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request) {
+	var body projectBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if body.Name == "" || s.findCluster(body.ClusterID) == nil {
+		writeError(w, http.StatusUnprocessableEntity, "name and a valid clusterId are required")
+		return
+	}
+	for _, p := range s.projects {
+		if p.ClusterID == body.ClusterID && p.Name == body.Name {
+			writeError(w, http.StatusConflict, fmt.Sprintf("project %s already exists", body.Name))
+			return
+		}
+	}
+	p := Project{ClusterID: body.ClusterID, Name: body.Name}
+	body.apply(&p)
+	writeJSON(w, http.StatusCreated, projectJSON(s.addProject(p)))
+}
+
+// handleUpdateProject updates the fields present in the body, like a Norman PUT.
+func (s *Server) handleUpdateProject(w http.ResponseWriter, r *http.Request) {
+	var body projectBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.projects {
+		if p.ID == r.PathValue("id") {
+			if body.Name != "" {
+				p.Name = body.Name
+			}
+			body.apply(p)
+			writeJSON(w, http.StatusOK, projectJSON(p))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("project %s not found", r.PathValue("id")))
+}
+
+// steveProjectBody is the writable part of a management.cattle.io Project.
+type steveProjectBody struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		DisplayName                   string                 `json:"displayName"`
+		Description                   *string                `json:"description"`
+		ResourceQuota                 map[string]interface{} `json:"resourceQuota"`
+		NamespaceDefaultResourceQuota map[string]interface{} `json:"namespaceDefaultResourceQuota"`
+		ContainerDefaultResourceLimit map[string]interface{} `json:"containerDefaultResourceLimit"`
+	} `json:"spec"`
+}
+
+func (b *steveProjectBody) projectBody() *projectBody {
+	return &projectBody{
+		Name:                          b.Spec.DisplayName,
+		ClusterID:                     b.Metadata.Namespace,
+		Description:                   b.Spec.Description,
+		Labels:                        b.Metadata.Labels,
+		Annotations:                   b.Metadata.Annotations,
+		ResourceQuota:                 b.Spec.ResourceQuota,
+		NamespaceDefaultResourceQuota: b.Spec.NamespaceDefaultResourceQuota,
+		ContainerDefaultResourceLimit: b.Spec.ContainerDefaultResourceLimit,
+	}
+}
+
+func (s *Server) handleListSteveProjects(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := []interface{}{}
+	for _, p := range s.projects {
+		if p.ClusterID == r.PathValue("namespace") {
+			data = append(data, steveProjectJSON(p))
+		}
+	}
+	writeJSON(w, http.StatusOK, collection("management.cattle.io.project", data))
+}
+
+func (s *Server) handleGetSteveProject(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("namespace") + ":" + r.PathValue("name")
+	for _, p := range s.projects {
+		if p.ID == id {
+			writeJSON(w, http.StatusOK, steveProjectJSON(p))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("project %s not found", id))
+}
+
+// handleCreateSteveProject creates a project. Like Kubernetes, it does not reject duplicate display names.
+func (s *Server) handleCreateSteveProject(w http.ResponseWriter, r *http.Request) {
+	var body steveProjectBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if body.Spec.DisplayName == "" || s.findCluster(body.Metadata.Namespace) == nil {
+		writeError(w, http.StatusUnprocessableEntity, "spec.displayName and a cluster namespace are required")
+		return
+	}
+	p := Project{ClusterID: body.Metadata.Namespace, Name: body.Spec.DisplayName}
+	if body.Metadata.Name != "" {
+		p.ID = p.ClusterID + ":" + body.Metadata.Name
+	}
+	body.projectBody().apply(&p)
+	writeJSON(w, http.StatusCreated, steveProjectJSON(s.addProject(p)))
+}
+
+// handleUpdateSteveProject replaces a project with the body, like a Kubernetes PUT.
+func (s *Server) handleUpdateSteveProject(w http.ResponseWriter, r *http.Request) {
+	var body steveProjectBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("namespace") + ":" + r.PathValue("name")
+	for _, p := range s.projects {
+		if p.ID == id {
+			*p = Project{ID: p.ID, ClusterID: p.ClusterID, Name: body.Spec.DisplayName}
+			body.projectBody().apply(p)
+			writeJSON(w, http.StatusOK, steveProjectJSON(p))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("project %s not found", id))
+}
+
+func (s *Server) handleListProjectMembers(w http.ResponseWriter, r *http.Request) {
+	projectID := r.URL.Query().Get("projectId")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := []interface{}{}
+	for _, m := range s.members {
+		if projectID == "" || m.ProjectID == projectID {
+			data = append(data, projectMemberJSON(m))
+		}
+	}
+	writeJSON(w, http.StatusOK, collection("projectRoleTemplateBinding", data))
+}
+
+func (s *Server) handleCreateProjectMember(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ProjectID        string `json:"projectId"`
+		RoleTemplateID   string `json:"roleTemplateId"`
+		UserPrincipalID  string `json:"userPrincipalId"`
+		GroupPrincipalID string `json:"groupPrincipalId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	known := false
+	for _, p := range s.projects {
+		known = known || p.ID == body.ProjectID
+	}
+	if !known || body.RoleTemplateID == "" || (body.UserPrincipalID == "") == (body.GroupPrincipalID == "") {
+		writeError(w, http.StatusUnprocessableEntity, "a valid projectId, a roleTemplateId and either userPrincipalId or groupPrincipalId are required")
+		return
+	}
+	m := s.addProjectMember(ProjectMember{
+		ProjectID:        body.ProjectID,
+		RoleTemplateID:   body.RoleTemplateID,
+		UserPrincipalID:  body.UserPrincipalID,
+		GroupPrincipalID: body.GroupPrincipalID,
+	})
+	writeJSON(w, http.StatusCreated, projectMemberJSON(m))
+}
+
+func (s *Server) handleListProjectNetworkPolicies(w http.ResponseWriter, r *http.Request) {
+	projectID := r.URL.Query().Get("projectId")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := []interface{}{}
+	for _, p := range s.projectNetworkPolicies {
+		if projectID == "" || p.ProjectID == projectID {
+			data = append(data, projectNetworkPolicyJSON(p))
+		}
+	}
+	writeJSON(w, http.StatusOK, collection("projectNetworkPolicy", data))
+}
+
+func (s *Server) handleCreateProjectNetworkPolicy(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ProjectID   string `json:"projectId"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	known := false
+	for _, p := range s.projects {
+		known = known || p.ID == body.ProjectID
+	}
+	if !known || body.Name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "a valid projectId and a name are required")
+		return
+	}
+	p := s.addProjectNetworkPolicy(ProjectNetworkPolicy{ProjectID: body.ProjectID, Name: body.Name, Description: body.Description})
+	writeJSON(w, http.StatusCreated, projectNetworkPolicyJSON(p))
+}
+
+func (s *Server) handleListNamespaces(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findCluster(r.PathValue("cluster")) == nil {
+		writeError(w, http.StatusNotFound, "cluster not found")
+		return
+	}
+	data := []interface{}{}
+	for _, ns := range s.sortedNamespaces(r.PathValue("cluster")) {
+		data = append(data, namespaceJSON(ns))
+	}
+	writeJSON(w, http.StatusOK, collection("namespace", data))
+}
+
+func (s *Server) handleGetNamespace(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns, ok := s.clusterNamespaces(r.PathValue("cluster"))[r.PathValue("name")]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("namespaces %q not found", r.PathValue("name")))
+		return
+	}
+	writeJSON(w, http.StatusOK, namespaceJSON(ns))
+}
+
+// namespaceBody is the subset of a Steve namespace object accepted on create and update.
+type namespaceBody struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+}
+
+func (s *Server) handleCreateNamespace(w http.ResponseWriter, r *http.Request) {
+	var body namespaceBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Metadata.Name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "metadata.name is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findCluster(r.PathValue("cluster")) == nil {
+		writeError(w, http.StatusNotFound, "cluster not found")
+		return
+	}
+	namespaces := s.clusterNamespaces(r.PathValue("cluster"))
+	if _, exists := namespaces[body.Metadata.Name]; exists {
+		writeError(w, http.StatusConflict, fmt.Sprintf("namespaces %q already exists", body.Metadata.Name))
+		return
+	}
+	ns := &Namespace{
+		Name:        body.Metadata.Name,
+		Labels:      copyMap(body.Metadata.Labels),
+		Annotations: copyMap(body.Metadata.Annotations),
+		Created:     time.Now().UTC().Truncate(time.Second),
+	}
+	namespaces[ns.Name] = ns
+	writeJSON(w, http.StatusCreated, namespaceJSON(ns))
+}
+
+// handleUpdateNamespace replaces the namespace's labels and annotations, like a Kubernetes PUT.
+func (s *Server) handleUpdateNamespace(w http.ResponseWriter, r *http.Request) {
+	var body namespaceBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns, ok := s.clusterNamespaces(r.PathValue("cluster"))[r.PathValue("name")]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("namespaces %q not found", r.PathValue("name")))
+		return
+	}
+	if body.Metadata.Name != "" && body.Metadata.Name != ns.Name {
+		writeError(w, http.StatusBadRequest, "metadata.name does not match")
+		return
+	}
+	ns.Labels = copyMap(body.Metadata.Labels)
+	ns.Annotations = copyMap(body.Metadata.Annotations)
+	writeJSON(w, http.StatusOK, namespaceJSON(ns))
+}
+
+func (s *Server) handleCreateNetworkPolicy(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Metadata struct {
+			Name      string            `json:"name"`
+			Namespace string            `json:"namespace"`
+			Labels    map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec map[string]interface{} `json:"spec"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Metadata.Name == "" || body.Metadata.Namespace == "" {
+		writeError(w, http.StatusUnprocessableEntity, "metadata.name and metadata.namespace are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clusterID := r.PathValue("cluster")
+	if s.findCluster(clusterID) == nil {
+		writeError(w, http.StatusNotFound, "cluster not found")
+		return
+	}
+	if _, ok := s.clusterNamespaces(clusterID)[body.Metadata.Namespace]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("namespaces %q not found", body.Metadata.Namespace))
+		return
+	}
+	for _, p := range s.networkPolicies[clusterID] {
+		if p.Namespace == body.Metadata.Namespace && p.Name == body.Metadata.Name {
+			writeError(w, http.StatusConflict, fmt.Sprintf("networkpolicies.networking.k8s.io %q already exists", body.Metadata.Name))
+			return
+		}
+	}
+	p := &NetworkPolicy{Namespace: body.Metadata.Namespace, Name: body.Metadata.Name, Labels: copyMap(body.Metadata.Labels), Spec: body.Spec}
+	s.networkPolicies[clusterID] = append(s.networkPolicies[clusterID], p)
+	writeJSON(w, http.StatusCreated, networkPolicyJSON(p))
+}
+
+func (s *Server) findCluster(id string) *Cluster {
+	for _, c := range s.clusters {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Server) sortedNamespaces(clusterID string) []*Namespace {
+	var namespaces []*Namespace
+	for _, ns := range s.clusterNamespaces(clusterID) {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+	return namespaces
+}
+
+func (s *Server) clusterJSON(c *Cluster) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          c.ID,
+		"type":        "cluster",
+		"name":        c.Name,
+		"provider":    c.Provider,
+		"driver":      c.Driver,
+		"state":       c.State,
+		"labels":      c.Labels,
+		"annotations": c.Annotations,
+		"created":     c.Created.Format(time.RFC3339),
+		"version":     map[string]string{"gitVersion": c.KubernetesVersion},
+		"nodeCount":   c.NodeCount,
+		"capacity":    map[string]string{"cpu": c.CPU, "memory": c.Memory},
+
+		"enableNetworkPolicy": c.EnableNetworkPolicy,
+		"links": map[string]string{
+			"self":     s.URL + "/v3/clusters/" + c.ID,
+			"projects": s.URL + "/v3/clusters/" + c.ID + "/projects",
+		},
+	}
+}
+
+func (s *Server) handleListManagementClusters(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := []interface{}{}
+	for _, c := range s.clusters {
+		data = append(data, map[string]interface{}{
+			"id":         c.ID,
+			"type":       "management.cattle.io.cluster",
+			"apiVersion": "management.cattle.io/v3",
+			"kind":       "Cluster",
+			"metadata":   steveMetadata(c.ID, "", c.Labels, c.Annotations, c.Created, c.State),
+			"spec": map[string]interface{}{
+				"displayName":         c.Name,
+				"enableNetworkPolicy": c.EnableNetworkPolicy,
+			},
+			"status": map[string]interface{}{
+				"provider":  c.Provider,
+				"driver":    c.Driver,
+				"version":   map[string]string{"gitVersion": c.KubernetesVersion},
+				"nodeCount": c.NodeCount,
+				"capacity":  map[string]string{"cpu": c.CPU, "memory": c.Memory},
+			},
+		})
+	}
+	writeJSON(w, http.StatusOK, collection("management.cattle.io.cluster", data))
+}
+
+func (s *Server) handleListProvisioningClusters(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := []interface{}{}
+	for _, c := range s.clusters {
+		spec := map[string]interface{}{"kubernetesVersion": c.KubernetesVersion}
+		if len(c.MachinePools) > 0 {
+			pools := []map[string]interface{}{}
+			for _, p := range c.MachinePools {
+				kind, name, _ := strings.Cut(p.MachineConfig, "/")
+				pools = append(pools, map[string]interface{}{
+					"name":             p.Name,
+					"quantity":         p.Quantity,
+					"etcdRole":         p.EtcdRole,
+					"controlPlaneRole": p.ControlPlaneRole,
+					"workerRole":       p.WorkerRole,
+					"machineConfigRef": map[string]string{"kind": kind, "name": name},
+				})
+			}
+			spec["rkeConfig"] = map[string]interface{}{"machinePools": pools}
+		}
+		data = append(data, map[string]interface{}{
+			"id":         c.FleetWorkspace + "/" + c.Name,
+			"type":       "provisioning.cattle.io.cluster",
+			"apiVersion": "provisioning.cattle.io/v1",
+			"kind":       "Cluster",
+			"metadata":   steveMetadata(c.Name, c.FleetWorkspace, c.Labels, c.Annotations, c.Created, c.State),
+			"spec":       spec,
+			"status":     map[string]interface{}{"clusterName": c.ID, "ready": c.State == "active"},
+		})
+	}
+	writeJSON(w, http.StatusOK, collection("provisioning.cattle.io.cluster", data))
+}
+
+// steveMetadata returns the metadata of a Steve object, including the state summary Steve adds.
+func steveMetadata(name, namespace string, labels, annotations map[string]string, created time.Time, state string) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"name":              name,
+		"labels":            labels,
+		"annotations":       annotations,
+		"creationTimestamp": created.Format(time.RFC3339),
+		"state":             map[string]interface{}{"name": state, "error": state != "active", "transitioning": false},
+	}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	return metadata
+}
+
+func steveProjectJSON(p *Project) map[string]interface{} {
+	clusterID, name, _ := strings.Cut(p.ID, ":")
+	return map[string]interface{}{
+		"id":         clusterID + "/" + name,
+		"type":       "management.cattle.io.project",
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       "Project",
+		"metadata":   steveMetadata(name, clusterID, p.Labels, p.Annotations, time.Time{}, "active"),
+		"spec": map[string]interface{}{
+			"displayName": p.Name,
+			"clusterName": clusterID,
+			"description": p.Description,
+
+			"resourceQuota":                 p.ResourceQuota,
+			"namespaceDefaultResourceQuota": p.NamespaceDefaultResourceQuota,
+			"containerDefaultResourceLimit": p.ContainerDefaultResourceLimit,
+		},
+	}
+}
+
+func projectJSON(p *Project) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          p.ID,
+		"type":        "project",
+		"baseType":    "project",
+		"name":        p.Name,
+		"clusterId":   p.ClusterID,
+		"description": p.Description,
+		"state":       "active",
+		"labels":      p.Labels,
+		"annotations": p.Annotations,
+
+		"resourceQuota":                 p.ResourceQuota,
+		"namespaceDefaultResourceQuota": p.NamespaceDefaultResourceQuota,
+		"containerDefaultResourceLimit": p.ContainerDefaultResourceLimit,
+	}
+}
+
+func projectMemberJSON(m *ProjectMember) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               m.ID,
+		"type":             "projectRoleTemplateBinding",
+		"projectId":        m.ProjectID,
+		"roleTemplateId":   m.RoleTemplateID,
+		"userPrincipalId":  m.UserPrincipalID,
+		"groupPrincipalId": m.GroupPrincipalID,
+	}
+}
+
+func projectNetworkPolicyJSON(p *ProjectNetworkPolicy) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          p.ID,
+		"type":        "projectNetworkPolicy",
+		"projectId":   p.ProjectID,
+		"name":        p.Name,
+		"description": p.Description,
+	}
+}
+
+func networkPolicyJSON(p *NetworkPolicy) map[string]interface{} {
+	return map[string]interface{}{
+		"id":   p.Namespace + "/" + p.Name,
+		"type": "networking.k8s.io.networkpolicy",
+		"metadata": map[string]interface{}{
+			"name":      p.Name,
+			"namespace": p.Namespace,
+			"labels":    p.Labels,
+		},
+		"spec": p.Spec,
+	}
+}
+
+func namespaceJSON(ns *Namespace) map[string]interface{} {
+	return map[string]interface{}{
+		"id":   ns.Name,
+		"type": "namespace",
+		"metadata": map[string]interface{}{
+			"name":              ns.Name,
+			"labels":            ns.Labels,
+			"annotations":       ns.Annotations,
+			"creationTimestamp": ns.Created.Format(time.RFC3339),
+		},
+		"spec":   map[string]interface{}{"finalizers": []string{"kubernetes"}},
+		"status": map[string]interface{}{"phase": "Active"},
+	}
+}
+
+func collection(resourceType string, data []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":         "collection",
+		"resourceType": resourceType,
+		"pagination":   map[string]int{"limit": 1000, "total": len(data)},
+		"data":         data,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"type":    "error",
+		"status":  status,
+		"code":    strings.ReplaceAll(http.StatusText(status), " ", ""),
+		"message": message,
+	})
+}
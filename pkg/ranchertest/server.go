@@ -0,0 +1,545 @@
+// Package ranchertest provides an in-memory fake of the Rancher API for tests.
+//
+// The fake implements the subset of the Norman (/v3) and Steve (/v1 and /k8s/clusters/<id>/v1)
+// APIs used by rancher-projects: clusters, projects, kubeconfig generation, and
+// namespaces and network policies through the cluster proxy. State is kept in memory and can be seeded
+// and inspected through the Server methods.
+//
+//	srv := ranchertest.NewServer()
+//	defer srv.Close()
+//	cluster := srv.AddCluster(ranchertest.Cluster{Name: "prod", Provider: "rke2"})
+//	cfg := srv.Config()
+//	cfg.ClusterName = cluster.Name
+package ranchertest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+)
+
+// Default credentials accepted by a new Server.
+const (
+	AccessKey = "token-fake"
+	SecretKey = "fakesecret"
+	Username  = "admin"
+)
+
+// ProjectIDAnnotation is the namespace annotation (and label) linking a namespace to a project.
+const ProjectIDAnnotation = "field.cattle.io/projectId"
+
+// Cluster is a downstream cluster known to the fake server.
+type Cluster struct {
+	ID          string
+	Name        string
+	Provider    string
+	Driver      string
+	State       string
+	Labels      map[string]string
+	Annotations map[string]string
+	Created     time.Time
+
+	// Inventory details reported by Rancher.
+	KubernetesVersion string
+	NodeCount         int
+	CPU               string
+	Memory            string
+
+	// Provisioning details reported by the Steve API. FleetWorkspace defaults to fleet-default.
+	FleetWorkspace string
+	MachinePools   []MachinePool
+
+	// EnableNetworkPolicy is the cluster's project network isolation setting.
+	EnableNetworkPolicy bool
+}
+
+// MachinePool is a machine pool of a cluster provisioned by Rancher.
+type MachinePool struct {
+	Name             string
+	Quantity         int
+	EtcdRole         bool
+	ControlPlaneRole bool
+	WorkerRole       bool
+	MachineConfig    string
+}
+
+// Project is a Rancher project known to the fake server. ID has the form <clusterID>:<projectID>.
+type Project struct {
+	ID          string
+	ClusterID   string
+	Name        string
+	Description string
+	Labels      map[string]string
+	Annotations map[string]string
+
+	// Quotas and limits, stored as sent by the client.
+	ResourceQuota                 map[string]interface{}
+	NamespaceDefaultResourceQuota map[string]interface{}
+	ContainerDefaultResourceLimit map[string]interface{}
+}
+
+// ProjectMember is a project role template binding, granting a user or group a role in a project.
+type ProjectMember struct {
+	ID               string
+	ProjectID        string
+	RoleTemplateID   string
+	UserPrincipalID  string
+	GroupPrincipalID string
+}
+
+// ProjectNetworkPolicy is a Rancher project network policy, the object through which Rancher
+// isolates the namespaces of a project from other projects.
+type ProjectNetworkPolicy struct {
+	ID          string
+	ProjectID   string
+	Name        string
+	Description string
+}
+
+// NetworkPolicy is a Kubernetes NetworkPolicy created through the cluster proxy. Spec is stored as sent.
+type NetworkPolicy struct {
+	Namespace string
+	Name      string
+	Labels    map[string]string
+	Spec      map[string]interface{}
+}
+
+// Namespace is a namespace in a downstream cluster known to the fake server.
+type Namespace struct {
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+	Created     time.Time
+}
+
+// ProjectID returns the namespace's field.cattle.io/projectId annotation.
+func (n Namespace) ProjectID() string {
+	return n.Annotations[ProjectIDAnnotation]
+}
+
+// Request is a request received by the fake server.
+type Request struct {
+	Method   string
+	Path     string
+	RawQuery string
+	Body     string
+}
+
+// Server is a fake Rancher API server backed by an httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	// AccessKey and SecretKey are the credentials the server accepts, either as
+	// basic auth or as a bearer token of the form AccessKey:SecretKey.
+	AccessKey string
+	SecretKey string
+	// Username is the user the credentials belong to, returned by /v3/users?me=true.
+	Username string
+
+	mu         sync.Mutex
+	clusters   []*Cluster
+	projects   []*Project
+	members    []*ProjectMember
+	namespaces map[string]map[string]*Namespace
+	settings   map[string]string
+	failures   []failure
+	hooks      []hook
+	requests   []Request
+	nextID     int
+
+	projectNetworkPolicies []*ProjectNetworkPolicy
+	networkPolicies        map[string][]*NetworkPolicy
+}
+
+type failure struct {
+	method string
+	prefix string
+	status int
+}
+
+type hook struct {
+	method string
+	prefix string
+	fn     func()
+}
+
+// NewServer starts a fake Rancher server over plain HTTP.
+func NewServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewServer(s.handler())
+	return s
+}
+
+// NewTLSServer starts a fake Rancher server over HTTPS using a self-signed certificate.
+func NewTLSServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewTLSServer(s.handler())
+	return s
+}
+
+func newServer() *Server {
+	return &Server{
+		AccessKey:  AccessKey,
+		SecretKey:  SecretKey,
+		Username:   Username,
+		namespaces: map[string]map[string]*Namespace{},
+		settings:   map[string]string{"cacerts": ""},
+
+		networkPolicies: map[string][]*NetworkPolicy{},
+	}
+}
+
+// Config returns a configuration pointing at the fake server with valid credentials.
+func (s *Server) Config() *config.Config {
+	return &config.Config{
+		RancherServerURL: s.URL,
+		RancherAccessKey: s.AccessKey,
+		RancherSecretKey: s.SecretKey,
+	}
+}
+
+// AddCluster registers a cluster. Missing IDs are generated and the state defaults to active.
+func (s *Server) AddCluster(c Cluster) Cluster {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c.ID == "" {
+		c.ID = s.newID("c-m-")
+	}
+	if c.State == "" {
+		c.State = "active"
+	}
+	if c.Created.IsZero() {
+		c.Created = time.Now().UTC().Truncate(time.Second)
+	}
+	if c.FleetWorkspace == "" {
+		c.FleetWorkspace = "fleet-default"
+	}
+	c.Labels = copyMap(c.Labels)
+	c.Annotations = copyMap(c.Annotations)
+	s.clusters = append(s.clusters, &c)
+	s.namespaces[c.ID] = map[string]*Namespace{}
+	return c
+}
+
+// AddProject registers a project in the given cluster and returns it.
+func (s *Server) AddProject(clusterID, name string) Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.addProject(Project{ClusterID: clusterID, Name: name})
+}
+
+func (s *Server) addProject(p Project) *Project {
+	if p.ID == "" {
+		p.ID = p.ClusterID + ":" + s.newID("p-")
+	}
+	p.Labels = copyMap(p.Labels)
+	p.Annotations = copyMap(p.Annotations)
+	s.projects = append(s.projects, &p)
+	return &p
+}
+
+// AddNamespace registers a namespace in the given cluster. When projectID is set the
+// namespace is assigned to that project.
+func (s *Server) AddNamespace(clusterID, name, projectID string) Namespace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns := &Namespace{Name: name, Labels: map[string]string{}, Annotations: map[string]string{}, Created: time.Now().UTC().Truncate(time.Second)}
+	if projectID != "" {
+		ns.Annotations[ProjectIDAnnotation] = projectID
+		ns.Labels[ProjectIDAnnotation] = projectID[strings.Index(projectID, ":")+1:]
+	}
+	s.clusterNamespaces(clusterID)[name] = ns
+	return copyNamespace(ns)
+}
+
+// UpdateNamespace applies fn to a stored namespace, e.g. to set labels in a test.
+func (s *Server) UpdateNamespace(clusterID, name string, fn func(ns *Namespace)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns, ok := s.clusterNamespaces(clusterID)[name]
+	if ok {
+		fn(ns)
+	}
+	return ok
+}
+
+// UpdateProject applies fn to a stored project, e.g. to set a description in a test.
+func (s *Server) UpdateProject(clusterID, name string, fn func(p *Project)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.projects {
+		if p.ClusterID == clusterID && p.Name == name {
+			fn(p)
+			return true
+		}
+	}
+	return false
+}
+
+// AddProjectMember registers a project role template binding.
+func (s *Server) AddProjectMember(m ProjectMember) ProjectMember {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.addProjectMember(m)
+}
+
+func (s *Server) addProjectMember(m ProjectMember) *ProjectMember {
+	if m.ID == "" {
+		m.ID = m.ProjectID[strings.Index(m.ProjectID, ":")+1:] + ":" + s.newID("prtb-")
+	}
+	s.members = append(s.members, &m)
+	return &m
+}
+
+// ProjectMembers returns the role template bindings of a project.
+func (s *Server) ProjectMembers(projectID string) []ProjectMember {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var members []ProjectMember
+	for _, m := range s.members {
+		if m.ProjectID == projectID {
+			members = append(members, *m)
+		}
+	}
+	return members
+}
+
+// AddProjectNetworkPolicy registers a project network policy.
+func (s *Server) AddProjectNetworkPolicy(p ProjectNetworkPolicy) ProjectNetworkPolicy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.addProjectNetworkPolicy(p)
+}
+
+func (s *Server) addProjectNetworkPolicy(p ProjectNetworkPolicy) *ProjectNetworkPolicy {
+	if p.ID == "" {
+		p.ID = p.ProjectID[strings.Index(p.ProjectID, ":")+1:] + ":" + s.newID("pnp-")
+	}
+	s.projectNetworkPolicies = append(s.projectNetworkPolicies, &p)
+	return &p
+}
+
+// ProjectNetworkPolicies returns the project network policies of a project.
+func (s *Server) ProjectNetworkPolicies(projectID string) []ProjectNetworkPolicy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var policies []ProjectNetworkPolicy
+	for _, p := range s.projectNetworkPolicies {
+		if p.ProjectID == projectID {
+			policies = append(policies, *p)
+		}
+	}
+	return policies
+}
+
+// NetworkPolicies returns the NetworkPolicies of a namespace in a cluster.
+func (s *Server) NetworkPolicies(clusterID, namespace string) []NetworkPolicy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var policies []NetworkPolicy
+	for _, p := range s.networkPolicies[clusterID] {
+		if p.Namespace == namespace {
+			c := *p
+			c.Labels = copyMap(p.Labels)
+			policies = append(policies, c)
+		}
+	}
+	return policies
+}
+
+// SetSetting sets a Rancher setting served under /v3/settings/<name>.
+func (s *Server) SetSetting(name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings[name] = value
+}
+
+// Fail makes every request with the given method whose path starts with prefix
+// fail with status. An empty method matches any method.
+func (s *Server) Fail(method, prefix string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{method: method, prefix: prefix, status: status})
+}
+
+// OnRequest calls fn before every request with the given method whose path starts with prefix is
+// handled, e.g. to change the server state between two requests of the code under test. An empty
+// method matches any method.
+func (s *Server) OnRequest(method, prefix string, fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, hook{method: method, prefix: prefix, fn: fn})
+}
+
+// Clusters returns all registered clusters.
+func (s *Server) Clusters() []Cluster {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clusters := make([]Cluster, 0, len(s.clusters))
+	for _, c := range s.clusters {
+		clusters = append(clusters, *c)
+	}
+	return clusters
+}
+
+// Projects returns the projects of a cluster.
+func (s *Server) Projects(clusterID string) []Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var projects []Project
+	for _, p := range s.projects {
+		if p.ClusterID == clusterID {
+			projects = append(projects, *p)
+		}
+	}
+	return projects
+}
+
+// Project returns the project with the given name in a cluster.
+func (s *Server) Project(clusterID, name string) (Project, bool) {
+	for _, p := range s.Projects(clusterID) {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Project{}, false
+}
+
+// Namespaces returns the namespaces of a cluster sorted by name.
+func (s *Server) Namespaces(clusterID string) []Namespace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var namespaces []Namespace
+	for _, ns := range s.sortedNamespaces(clusterID) {
+		namespaces = append(namespaces, copyNamespace(ns))
+	}
+	return namespaces
+}
+
+// Namespace returns a namespace of a cluster.
+func (s *Server) Namespace(clusterID, name string) (Namespace, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns, ok := s.clusterNamespaces(clusterID)[name]
+	if !ok {
+		return Namespace{}, false
+	}
+	return copyNamespace(ns), true
+}
+
+// Requests returns every request received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// CountRequests returns the number of requests with the given method whose path starts with prefix.
+func (s *Server) CountRequests(method, prefix string) int {
+	count := 0
+	for _, r := range s.Requests() {
+		if (method == "" || r.Method == method) && strings.HasPrefix(r.Path, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Server) clusterNamespaces(clusterID string) map[string]*Namespace {
+	namespaces, ok := s.namespaces[clusterID]
+	if !ok {
+		namespaces = map[string]*Namespace{}
+		s.namespaces[clusterID] = namespaces
+	}
+	return namespaces
+}
+
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s%05d", prefix, s.nextID)
+}
+
+// authorized reports whether the request carries the server's credentials.
+func (s *Server) authorized(r *http.Request) bool {
+	token := s.AccessKey + ":" + s.SecretKey
+	auth := r.Header.Get("Authorization")
+	switch {
+	case strings.HasPrefix(auth, "Bearer "):
+		return strings.TrimPrefix(auth, "Bearer ") == token
+	case strings.HasPrefix(auth, "Basic "):
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(auth, "Basic "))
+		return err == nil && string(decoded) == token
+	}
+	return false
+}
+
+// middleware records requests, runs hooks, applies injected failures and enforces authentication.
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, RawQuery: r.URL.RawQuery, Body: string(body)})
+		status := 0
+		for _, f := range s.failures {
+			if (f.method == "" || f.method == r.Method) && strings.HasPrefix(r.URL.Path, f.prefix) {
+				status = f.status
+			}
+		}
+		var hooks []func()
+		for _, h := range s.hooks {
+			if (h.method == "" || h.method == r.Method) && strings.HasPrefix(r.URL.Path, h.prefix) {
+				hooks = append(hooks, h.fn)
+			}
+		}
+		s.mu.Unlock()
+
+		for _, fn := range hooks {
+			fn()
+		}
+
+		if status != 0 {
+			writeError(w, status, "injected failure")
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, "/v3/settings/") && !s.authorized(r) {
+			writeError(w, http.StatusUnauthorized, "must authenticate")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func copyMap(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func copyNamespace(ns *Namespace) Namespace {
+	c := *ns
+	c.Labels = copyMap(ns.Labels)
+	c.Annotations = copyMap(ns.Annotations)
+	return c
+}
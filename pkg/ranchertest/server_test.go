@@ -0,0 +1,53 @@
+package ranchertest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerRequiresAuthentication(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v3/clusters")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, _ := http.NewRequest("GET", srv.URL+"/v3/clusters", nil)
+	req.Header.Set("Authorization", "Bearer "+AccessKey+":"+SecretKey)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "/v3/settings/cacerts")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServerNamespaces(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	cluster := srv.AddCluster(Cluster{Name: "prod"})
+	project := srv.AddProject(cluster.ID, "Apps")
+	srv.AddNamespace(cluster.ID, "web", project.ID)
+
+	ns, ok := srv.Namespace(cluster.ID, "web")
+	require.True(t, ok)
+	assert.Equal(t, project.ID, ns.ProjectID())
+	assert.Equal(t, strings.TrimPrefix(project.ID, cluster.ID+":"), ns.Labels[ProjectIDAnnotation])
+
+	req, _ := http.NewRequest("POST", srv.URL+"/k8s/clusters/"+cluster.ID+"/v1/namespaces", strings.NewReader(`{"metadata":{"name":"web"}}`))
+	req.SetBasicAuth(AccessKey, SecretKey)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Equal(t, 1, srv.CountRequests("POST", "/k8s/clusters/"))
+}
@@ -0,0 +1,105 @@
+// Package rules maps namespaces to projects by name pattern or labels, so namespaces created by
+// other tools, such as Helm or Argo CD, can be assigned to the right project automatically.
+//
+// Example:
+//
+//	rules:
+//	  - namespace: '^team-a-.*'
+//	    project: TeamA
+//	  - matchLabels:
+//	      argocd.argoproj.io/instance: payments
+//	    project: Payments
+//	  - namespace: '^legacy-'
+//	    project: Legacy
+//	    reassign: true
+//
+// The first matching rule wins.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rules is an ordered list of rules.
+type Rules struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Rule assigns namespaces whose name matches Namespace and whose labels include MatchLabels to
+// Project. Either condition may be left out, but not both. Namespaces that are already in another
+// project are only moved when Reassign is set.
+type Rule struct {
+	Namespace   string            `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	MatchLabels map[string]string `yaml:"matchLabels,omitempty" json:"matchLabels,omitempty"`
+	Project     string            `yaml:"project" json:"project"`
+	Reassign    bool              `yaml:"reassign,omitempty" json:"reassign,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Load reads and validates the rules file at path.
+func Load(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+
+	var r Rules
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse rules %s: %w", path, err)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Validate compiles the namespace patterns and reports rules without a project or without any condition.
+func (r *Rules) Validate() error {
+	var problems []error
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.Project == "" {
+			problems = append(problems, fmt.Errorf("rule %d has no project", i+1))
+		}
+		if rule.Namespace == "" && len(rule.MatchLabels) == 0 {
+			problems = append(problems, fmt.Errorf("rule %d needs a namespace pattern or matchLabels", i+1))
+		}
+		if rule.Namespace != "" {
+			pattern, err := regexp.Compile(rule.Namespace)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("rule %d has an invalid namespace pattern: %w", i+1, err))
+			}
+			rule.pattern = pattern
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// Match returns the first rule matching a namespace, or nil. Validate must have been called.
+func (r *Rules) Match(name string, labels map[string]string) *Rule {
+	for i := range r.Rules {
+		if r.Rules[i].Matches(name, labels) {
+			return &r.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to a namespace.
+func (r *Rule) Matches(name string, labels map[string]string) bool {
+	if r.pattern != nil && !r.pattern.MatchString(name) {
+		return false
+	}
+	for k, v := range r.MatchLabels {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
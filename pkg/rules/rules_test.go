@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAndMatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rules:
+  - namespace: '^team-a-.*'
+    project: TeamA
+  - matchLabels:
+      argocd.argoproj.io/instance: payments
+    project: Payments
+  - namespace: '-db$'
+    matchLabels:
+      tier: data
+    project: Data
+    reassign: true
+`), 0o600))
+
+	r, err := Load(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		project string
+	}{
+		{"team-a-dev", nil, "TeamA"},
+		{"team-b-dev", nil, ""},
+		{"checkout", map[string]string{"argocd.argoproj.io/instance": "payments"}, "Payments"},
+		{"checkout", map[string]string{"argocd.argoproj.io/instance": "billing"}, ""},
+		{"orders-db", map[string]string{"tier": "data"}, "Data"},
+		{"orders-db", nil, ""},
+		// The first matching rule wins.
+		{"team-a-db", map[string]string{"tier": "data"}, "TeamA"},
+	}
+	for _, test := range tests {
+		rule := r.Match(test.name, test.labels)
+		if test.project == "" {
+			assert.Nil(t, rule, test.name)
+			continue
+		}
+		require.NotNil(t, rule, test.name)
+		assert.Equal(t, test.project, rule.Project, test.name)
+	}
+	assert.True(t, r.Match("orders-db", map[string]string{"tier": "data"}).Reassign)
+}
+
+func TestValidate(t *testing.T) {
+	r := &Rules{Rules: []Rule{
+		{Namespace: "^ok$", Project: "A"},
+		{Namespace: "("},
+		{Project: "B"},
+	}}
+	err := r.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rule 2 has no project")
+	assert.Contains(t, err.Error(), "rule 2 has an invalid namespace pattern")
+	assert.Contains(t, err.Error(), "rule 3 needs a namespace pattern or matchLabels")
+	assert.NotContains(t, err.Error(), "rule 1")
+}
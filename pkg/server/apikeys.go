@@ -0,0 +1,62 @@
+package server
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// APIKeys maps API keys accepted by the server to the name of the caller they identify.
+type APIKeys map[string]string
+
+// LoadAPIKeys reads API keys from a file with one "name:key" entry per line. A line without a
+// name is named after its line number. Empty lines and lines starting with # are ignored.
+func LoadAPIKeys(path string) (APIKeys, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %w", err)
+	}
+	defer file.Close()
+
+	keys := APIKeys{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		name, key, found := strings.Cut(entry, ":")
+		if !found {
+			name, key = fmt.Sprintf("key-%d", line), entry
+		}
+		name, key = strings.TrimSpace(name), strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("%s:%d: empty API key", path, line)
+		}
+		if _, exists := keys[key]; exists {
+			return nil, fmt.Errorf("%s:%d: duplicate API key", path, line)
+		}
+		keys[key] = name
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no API keys found in %s", path)
+	}
+	return keys, nil
+}
+
+// Lookup returns the caller name for key. Every key is compared in constant time.
+func (k APIKeys) Lookup(key string) (string, bool) {
+	var caller string
+	found := false
+	for candidate, name := range k {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			caller, found = name, true
+		}
+	}
+	return caller, found
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/rancher"
+)
+
+// namePattern is the format accepted for cluster and project names: letters, digits, spaces,
+// dots, underscores and dashes, starting and ending with a letter or digit.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9 ._]*[A-Za-z0-9])?$`)
+
+// namespacePattern is the Kubernetes DNS-1123 label format namespace names must follow.
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// ProjectRequest is the body of POST /clusters/{cluster}/projects.
+type ProjectRequest struct {
+	Name string `json:"name"`
+}
+
+// NamespaceRequest is the body of POST /clusters/{cluster}/projects/{project}/namespaces.
+type NamespaceRequest struct {
+	Name          string `json:"name"`
+	CreateProject bool   `json:"createProject"`
+}
+
+// ProjectResponse describes a project.
+type ProjectResponse struct {
+	Cluster   string `json:"cluster"`
+	ClusterID string `json:"clusterId"`
+	Project   string `json:"project"`
+	ProjectID string `json:"projectId"`
+	Created   bool   `json:"created"`
+}
+
+// NamespaceResponse describes a namespace and the project it is assigned to.
+type NamespaceResponse struct {
+	Cluster        string `json:"cluster"`
+	ClusterID      string `json:"clusterId"`
+	Project        string `json:"project"`
+	ProjectID      string `json:"projectId"`
+	Namespace      string `json:"namespace"`
+	Created        bool   `json:"created"`
+	ProjectCreated bool   `json:"projectCreated"`
+}
+
+// NamespaceListResponse lists the namespaces of a project.
+type NamespaceListResponse struct {
+	Cluster    string   `json:"cluster"`
+	ClusterID  string   `json:"clusterId"`
+	Project    string   `json:"project"`
+	ProjectID  string   `json:"projectId"`
+	Namespaces []string `json:"namespaces"`
+}
+
+func validateName(kind, name string) error {
+	if name == "" || len(name) > 253 {
+		return fmt.Errorf("%s name must be between 1 and 253 characters", kind)
+	}
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%s name %q may only contain letters, digits, spaces, dots, underscores and dashes, and must start and end with a letter or digit", kind, name)
+	}
+	return nil
+}
+
+func validateNamespace(name string) error {
+	if len(name) > 63 || !namespacePattern.MatchString(name) {
+		return fmt.Errorf("namespace %q must be a lowercase RFC 1123 label of at most 63 characters", name)
+	}
+	return nil
+}
+
+// clusterConfig returns a copy of the server configuration for a request against the cluster
+// named in the path and resolves the cluster ID. Changes are audited on behalf of the caller.
+func (s *Server) clusterConfig(r *http.Request) (*config.Config, string, error) {
+	cfg := *s.cfg
+	cfg.ClusterName = r.PathValue("cluster")
+	cfg.Caller = Caller(r.Context())
+	cfg.ClusterType, cfg.ClusterLabels, cfg.KubernetesVersion = "", "", ""
+	cfg.CreateKubeconfig = false
+
+	clusterID, err := rancher.GetClusterID(&cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	return &cfg, clusterID, nil
+}
+
+func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request) {
+	var body ProjectRequest
+	if err := decodeBody(w, r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := errors.Join(validateName("cluster", r.PathValue("cluster")), validateName("project", body.Name)); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	cfg, clusterID, err := s.clusterConfig(r)
+	if err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+
+	resp := ProjectResponse{Cluster: cfg.ClusterName, ClusterID: clusterID, Project: body.Name}
+	resp.ProjectID, err = rancher.GetProjectInfo(cfg, clusterID, body.Name)
+	if errors.Is(err, rancher.ErrNotFound) {
+		if err = rancher.CreateProject(cfg, clusterID, body.Name); err == nil {
+			resp.Created = true
+			resp.ProjectID, err = rancher.GetProjectInfo(cfg, clusterID, body.Name)
+		}
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	status := http.StatusOK
+	if resp.Created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, resp)
+}
+
+func (s *Server) handleListNamespaces(w http.ResponseWriter, r *http.Request) {
+	if err := errors.Join(validateName("cluster", r.PathValue("cluster")), validateName("project", r.PathValue("project"))); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	cfg, clusterID, err := s.clusterConfig(r)
+	if err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	projectID, err := rancher.GetProjectInfo(cfg, clusterID, r.PathValue("project"))
+	if err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	namespaces, err := rancher.ListNamespaces(cfg, clusterID)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	resp := NamespaceListResponse{
+		Cluster:    cfg.ClusterName,
+		ClusterID:  clusterID,
+		Project:    r.PathValue("project"),
+		ProjectID:  projectID,
+		Namespaces: []string{},
+	}
+	for _, ns := range namespaces {
+		if ns.ProjectID() == projectID {
+			resp.Namespaces = append(resp.Namespaces, ns.Name)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleCreateNamespace creates a namespace if needed and assigns it to the project using the same
+// steps as the apply command. Namespaces that already belong to another project are never moved.
+func (s *Server) handleCreateNamespace(w http.ResponseWriter, r *http.Request) {
+	var body NamespaceRequest
+	if err := decodeBody(w, r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := errors.Join(
+		validateName("cluster", r.PathValue("cluster")),
+		validateName("project", r.PathValue("project")),
+		validateNamespace(body.Name),
+	); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	cfg, clusterID, err := s.clusterConfig(r)
+	if err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	cfg.ProjectName = r.PathValue("project")
+	cfg.Namespace = body.Name
+	cfg.CreateProject = body.CreateProject
+	cfg.CreateNamespace = true
+
+	projectID, err := rancher.GetProjectInfo(cfg, clusterID, cfg.ProjectName)
+	switch {
+	case errors.Is(err, rancher.ErrNotFound) && body.CreateProject:
+	case err != nil:
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+
+	existing, err := rancher.GetNamespace(cfg, clusterID, cfg.Namespace)
+	created := errors.Is(err, rancher.ErrNotFound)
+	switch {
+	case created:
+	case err != nil:
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	case existing.ProjectID() != "" && existing.ProjectID() != projectID:
+		writeError(w, http.StatusConflict, fmt.Sprintf("namespace %s already belongs to another project", cfg.Namespace))
+		return
+	}
+
+	if err := rancher.MainProject(cfg, clusterID); err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	projectCreated := projectID == ""
+	if projectCreated {
+		if projectID, err = rancher.GetProjectInfo(cfg, clusterID, cfg.ProjectName); err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, NamespaceResponse{
+		Cluster:        cfg.ClusterName,
+		ClusterID:      clusterID,
+		Project:        cfg.ProjectName,
+		ProjectID:      projectID,
+		Namespace:      cfg.Namespace,
+		Created:        created,
+		ProjectCreated: projectCreated,
+	})
+}
@@ -0,0 +1,160 @@
+// Package server exposes the project and namespace operations over an authenticated
+// HTTP API so internal portals can let developers serve themselves.
+//
+// Endpoints:
+//
+//	GET  /healthz
+//	GET  /metrics
+//	POST /clusters/{cluster}/projects                          {"name": "TeamA"}
+//	GET  /clusters/{cluster}/projects/{project}/namespaces
+//	POST /clusters/{cluster}/projects/{project}/namespaces     {"name": "team-a-dev", "createProject": false}
+//
+// Callers authenticate with "Authorization: Bearer <key>" or "X-API-Key: <key>".
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/supporttools/rancher-projects/pkg/config"
+	"github.com/supporttools/rancher-projects/pkg/logging"
+	"github.com/supporttools/rancher-projects/pkg/metrics"
+	"github.com/supporttools/rancher-projects/pkg/rancher"
+)
+
+var logger = logging.SetupLogging()
+
+// maxBodyBytes limits the size of request bodies.
+const maxBodyBytes = 1 << 20
+
+type callerKey struct{}
+
+// Caller returns the name of the authenticated caller of a request handled by the server.
+func Caller(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// Server handles self-service requests against the Rancher server of its configuration.
+type Server struct {
+	cfg  *config.Config
+	keys APIKeys
+}
+
+// New returns a server that uses cfg to talk to Rancher and accepts the given API keys.
+func New(cfg *config.Config, keys APIKeys) *Server {
+	return &Server{cfg: cfg, keys: keys}
+}
+
+// Handler returns the HTTP handler serving the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	handle := func(pattern string, handler http.Handler) {
+		mux.Handle(pattern, metrics.Middleware(pattern[strings.Index(pattern, " ")+1:], handler))
+	}
+
+	handle("GET /healthz", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}))
+	mux.Handle("GET /metrics", metrics.Handler())
+	handle("POST /clusters/{cluster}/projects", s.authenticate(s.handleCreateProject))
+	handle("GET /clusters/{cluster}/projects/{project}/namespaces", s.authenticate(s.handleListNamespaces))
+	handle("POST /clusters/{cluster}/projects/{project}/namespaces", s.authenticate(s.handleCreateNamespace))
+	return mux
+}
+
+// ListenAndServe serves the API on addr until ctx is cancelled, then shuts down gracefully.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info(fmt.Sprintf("Serving API on %s", addr))
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		logger.Info("Shutting down API server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// authenticate rejects requests without a valid API key and logs every authenticated request.
+func (s *Server) authenticate(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			key = strings.TrimPrefix(auth, "Bearer ")
+		}
+		caller, ok := s.keys.Lookup(key)
+		if key == "" || !ok {
+			logger.Warn(fmt.Sprintf("Rejected unauthenticated request %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr))
+			writeError(w, http.StatusUnauthorized, "missing or invalid API key")
+			return
+		}
+
+		logger.Info(fmt.Sprintf("API request %s %s by %s", r.Method, r.URL.Path, caller))
+		next(w, r.WithContext(context.WithValue(r.Context(), callerKey{}, caller)))
+	})
+}
+
+// decodeBody decodes a JSON request body, rejecting unknown fields and oversized bodies.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// errorResponse is the body of every failed request.
+type errorResponse struct {
+	Error   string   `json:"error"`
+	Details []string `json:"details,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, status int, message string, details ...string) {
+	writeJSON(w, status, errorResponse{Error: message, Details: details})
+}
+
+// writeValidationError responds with 400 and one detail per validation problem.
+func writeValidationError(w http.ResponseWriter, err error) {
+	var details []string
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			details = append(details, e.Error())
+		}
+	} else {
+		details = []string{err.Error()}
+	}
+	writeError(w, http.StatusBadRequest, "invalid request", details...)
+}
+
+// statusFor maps an error from pkg/rancher to an HTTP status.
+func statusFor(err error) int {
+	if errors.Is(err, rancher.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadGateway
+}
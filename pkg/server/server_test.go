@@ -0,0 +1,183 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supporttools/rancher-projects/pkg/audit"
+	"github.com/supporttools/rancher-projects/pkg/rancher"
+	"github.com/supporttools/rancher-projects/pkg/ranchertest"
+)
+
+const testKey = "s3cr3t-key"
+
+func TestMain(m *testing.M) {
+	rancher.NamespaceSettleDelay = 0
+	os.Exit(m.Run())
+}
+
+func newTestAPI(t *testing.T) (*ranchertest.Server, ranchertest.Cluster, *httptest.Server) {
+	t.Helper()
+	rancherSrv := ranchertest.NewServer()
+	t.Cleanup(rancherSrv.Close)
+	cluster := rancherSrv.AddCluster(ranchertest.Cluster{Name: "dev"})
+
+	api := httptest.NewServer(New(rancherSrv.Config(), APIKeys{testKey: "portal"}).Handler())
+	t.Cleanup(api.Close)
+	return rancherSrv, cluster, api
+}
+
+func call(t *testing.T, api *httptest.Server, method, path, key, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req, err := http.NewRequest(method, api.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	return resp.StatusCode, decoded
+}
+
+func TestCreateNamespace(t *testing.T) {
+	rancherSrv, cluster, api := newTestAPI(t)
+	project := rancherSrv.AddProject(cluster.ID, "TeamA")
+
+	status, body := call(t, api, "POST", "/clusters/dev/projects/TeamA/namespaces", testKey, `{"name":"team-a-dev"}`)
+	require.Equal(t, http.StatusCreated, status, body)
+	assert.Equal(t, project.ID, body["projectId"])
+	assert.Equal(t, true, body["created"])
+
+	ns, ok := rancherSrv.Namespace(cluster.ID, "team-a-dev")
+	require.True(t, ok)
+	assert.Equal(t, project.ID, ns.ProjectID())
+
+	// Repeating the request is harmless.
+	status, body = call(t, api, "POST", "/clusters/dev/projects/TeamA/namespaces", testKey, `{"name":"team-a-dev"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, false, body["created"])
+
+	status, body = call(t, api, "GET", "/clusters/dev/projects/TeamA/namespaces", testKey, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []interface{}{"team-a-dev"}, body["namespaces"])
+}
+
+func TestCreateNamespaceWithProject(t *testing.T) {
+	rancherSrv, cluster, api := newTestAPI(t)
+
+	status, body := call(t, api, "POST", "/clusters/dev/projects/TeamB/namespaces", testKey, `{"name":"team-b"}`)
+	assert.Equal(t, http.StatusNotFound, status, body)
+
+	status, body = call(t, api, "POST", "/clusters/dev/projects/TeamB/namespaces", testKey, `{"name":"team-b","createProject":true}`)
+	require.Equal(t, http.StatusCreated, status, body)
+	assert.Equal(t, true, body["projectCreated"])
+
+	project, ok := rancherSrv.Project(cluster.ID, "TeamB")
+	require.True(t, ok)
+	assert.Equal(t, project.ID, body["projectId"])
+}
+
+func TestCreateNamespaceRefusesToMoveNamespaces(t *testing.T) {
+	rancherSrv, cluster, api := newTestAPI(t)
+	other := rancherSrv.AddProject(cluster.ID, "Other")
+	rancherSrv.AddProject(cluster.ID, "TeamA")
+	rancherSrv.AddNamespace(cluster.ID, "shared", other.ID)
+
+	status, _ := call(t, api, "POST", "/clusters/dev/projects/TeamA/namespaces", testKey, `{"name":"shared"}`)
+	assert.Equal(t, http.StatusConflict, status)
+
+	ns, _ := rancherSrv.Namespace(cluster.ID, "shared")
+	assert.Equal(t, other.ID, ns.ProjectID())
+}
+
+func TestCreateProject(t *testing.T) {
+	rancherSrv, cluster, api := newTestAPI(t)
+
+	status, body := call(t, api, "POST", "/clusters/dev/projects", testKey, `{"name":"TeamC"}`)
+	require.Equal(t, http.StatusCreated, status, body)
+	_, ok := rancherSrv.Project(cluster.ID, "TeamC")
+	assert.True(t, ok)
+
+	status, _ = call(t, api, "POST", "/clusters/dev/projects", testKey, `{"name":"TeamC"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, rancherSrv.Projects(cluster.ID), 1)
+}
+
+func TestChangesAreAuditedForCaller(t *testing.T) {
+	rancherSrv := ranchertest.NewServer()
+	t.Cleanup(rancherSrv.Close)
+	rancherSrv.AddCluster(ranchertest.Cluster{Name: "dev"})
+	cfg := rancherSrv.Config()
+	cfg.AuditFile = filepath.Join(t.TempDir(), "audit.jsonl")
+	api := httptest.NewServer(New(cfg, APIKeys{testKey: "portal"}).Handler())
+	t.Cleanup(api.Close)
+
+	status, _ := call(t, api, "POST", "/clusters/dev/projects", testKey, `{"name":"TeamD"}`)
+	require.Equal(t, http.StatusCreated, status)
+
+	entries, err := audit.Read(cfg.AuditFile)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "portal", entries[0].Caller)
+	assert.Equal(t, "dev", entries[0].Cluster)
+	assert.Equal(t, "project/TeamD", entries[0].Object)
+}
+
+func TestRequestValidation(t *testing.T) {
+	_, _, api := newTestAPI(t)
+
+	tests := []struct {
+		name, method, path, key, body string
+		status                        int
+	}{
+		{"no key", "POST", "/clusters/dev/projects", "", `{"name":"x"}`, http.StatusUnauthorized},
+		{"wrong key", "POST", "/clusters/dev/projects", "nope", `{"name":"x"}`, http.StatusUnauthorized},
+		{"invalid namespace", "POST", "/clusters/dev/projects/TeamA/namespaces", testKey, `{"name":"Not_Valid"}`, http.StatusBadRequest},
+		{"unknown field", "POST", "/clusters/dev/projects/TeamA/namespaces", testKey, `{"name":"ok","owner":"me"}`, http.StatusBadRequest},
+		{"malformed body", "POST", "/clusters/dev/projects", testKey, `{`, http.StatusBadRequest},
+		{"empty project", "POST", "/clusters/dev/projects", testKey, `{"name":""}`, http.StatusBadRequest},
+		{"project name with query", "POST", "/clusters/dev/projects", testKey, `{"name":"x&clusterId=c-other"}`, http.StatusBadRequest},
+		{"cluster name with query", "POST", "/clusters/dev%26name=x/projects", testKey, `{"name":"x"}`, http.StatusBadRequest},
+		{"unknown cluster", "POST", "/clusters/nope/projects", testKey, `{"name":"x"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := call(t, api, tt.method, tt.path, tt.key, tt.body)
+			assert.Equal(t, tt.status, status, body)
+			assert.NotEmpty(t, body["error"])
+		})
+	}
+}
+
+func TestLoadAPIKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	require.NoError(t, os.WriteFile(path, []byte("# portal keys\nportal:abc\n\nxyz\n"), 0o600))
+
+	keys, err := LoadAPIKeys(path)
+	require.NoError(t, err)
+	caller, ok := keys.Lookup("abc")
+	assert.True(t, ok)
+	assert.Equal(t, "portal", caller)
+	caller, ok = keys.Lookup("xyz")
+	assert.True(t, ok)
+	assert.Equal(t, "key-4", caller)
+	_, ok = keys.Lookup("ab")
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(path, []byte("# nothing\n"), 0o600))
+	_, err = LoadAPIKeys(path)
+	assert.ErrorContains(t, err, "no API keys")
+}